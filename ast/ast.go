@@ -2,6 +2,8 @@ package ast
 
 import (
 	"bytes"
+	"math/big"
+	"strings"
 	"yokan/utility"
 	"yokan/token"
 )
@@ -78,7 +80,11 @@ func (as *Assign) TokenLiteral() string {
 
 func (as *Assign) String() string {
 	var out bytes.Buffer
-	out.WriteString(as.Name.String()+" = ")
+	out.WriteString(as.Name.String())
+	if as.Name.Type != nil {
+		out.WriteString(": "+as.Name.Type.String())
+	}
+	out.WriteString(" = ")
 	if as.Value != nil {
 		out.WriteString(as.Value.String())
 	}
@@ -87,6 +93,124 @@ func (as *Assign) String() string {
 }
 
 
+// 分割代入(文) [a, b] = pair
+
+type DestructuringAssign struct {
+	Token token.Token
+	Pattern Pattern
+	Value Expression
+}
+
+func (da *DestructuringAssign) statementNode() { }
+func (da *DestructuringAssign) TokenLiteral() string {
+	return da.Token.Literal
+}
+
+func (da *DestructuringAssign) String() string {
+	return da.Pattern.String()+" = "+da.Value.String()+"\n"
+}
+
+
+// 要素への代入(文) arr[i] = v, h["k"] = v
+
+type IndexAssign struct {
+	Token token.Token
+	Target *IndexExpression
+	Value Expression
+}
+
+func (ia *IndexAssign) statementNode() { }
+func (ia *IndexAssign) TokenLiteral() string {
+	return ia.Token.Literal
+}
+
+func (ia *IndexAssign) String() string {
+	return ia.Target.Left.String()+"["+ia.Target.Index.String()+"] = "+ia.Value.String()+"\n"
+}
+
+
+// フィールドとメソッドへの代入(文) p.x = 1, Point.norm = () => ...
+
+type MemberAssign struct {
+	Token token.Token
+	Target *MemberExpression
+	Value Expression
+}
+
+func (ma *MemberAssign) statementNode() { }
+func (ma *MemberAssign) TokenLiteral() string {
+	return ma.Token.Literal
+}
+
+func (ma *MemberAssign) String() string {
+	return ma.Target.String()+" = "+ma.Value.String()+"\n"
+}
+
+
+// 演算しながらの代入(文) x += 1, arr[i] *= 2
+
+type CompoundAssign struct {
+	Token token.Token
+	// *Identifier か *IndexExpression か *MemberExpression
+	Target Expression
+	// += なら +
+	Operator string
+	Value Expression
+}
+
+func (ca *CompoundAssign) statementNode() { }
+func (ca *CompoundAssign) TokenLiteral() string {
+	return ca.Token.Literal
+}
+
+func (ca *CompoundAssign) String() string {
+	target := ca.Target.String()
+	if ie, ok := ca.Target.(*IndexExpression); ok {
+		target = ie.Left.String()+"["+ie.Index.String()+"]"
+	}
+	return target+" "+ca.Operator+"= "+ca.Value.String()+"\n"
+}
+
+
+// 列挙型の宣言(文) enum Shape { Circle(r), Rect(w, h), Empty }
+
+type EnumStatement struct {
+	Token token.Token
+	Name Identifier
+	Variants []EnumVariant
+}
+
+type EnumVariant struct {
+	Name Identifier
+	// カッコのない Empty のような値ならnil
+	Fields []Identifier
+}
+
+func (es *EnumStatement) statementNode() { }
+func (es *EnumStatement) TokenLiteral() string {
+	return es.Token.Literal
+}
+
+func (es *EnumStatement) String() string {
+	var variants []string
+	for _, v := range es.Variants {
+		variants = append(variants, v.String())
+	}
+	return "enum "+es.Name.String()+" { "+strings.Join(variants, ", ")+" }\n"
+}
+
+func (ev EnumVariant) String() string {
+	if ev.Fields == nil {
+		return ev.Name.String()
+	}
+	var fields []string
+	for _, f := range ev.Fields {
+		fields = append(fields, f.Name)
+	}
+	return ev.Name.String()+"("+strings.Join(fields, ", ")+")"
+}
+
+
 // 前置演算子
 
 type PrefixExpression struct {
@@ -166,6 +290,60 @@ func (fc *FunctionCalling) String() string {
 }
 
 
+// 添字 arr[i], h["k"], s[0]
+
+type IndexExpression struct {
+	Token token.Token
+	Left Expression
+	Index Expression
+}
+
+func (ie *IndexExpression) expressionNode() { }
+func (ie *IndexExpression) TokenLiteral() string {
+	return ie.Token.Literal
+}
+
+func (ie *IndexExpression) String() string {
+	return "("+ie.Left.String()+"["+ie.Index.String()+"])"
+}
+
+
+// フィールドの取り出し p.x
+
+type MemberExpression struct {
+	Token token.Token
+	Object Expression
+	Member Identifier
+}
+
+func (me *MemberExpression) expressionNode() { }
+func (me *MemberExpression) TokenLiteral() string {
+	return me.Token.Literal
+}
+
+func (me *MemberExpression) String() string {
+	return me.Object.String()+"."+me.Member.String()
+}
+
+
+// 関数呼び出しの名前付き引数 f(x: 1) の x: 1
+
+type NamedArgument struct {
+	Token token.Token
+	Name Identifier
+	Value Expression
+}
+
+func (na *NamedArgument) expressionNode() { }
+func (na *NamedArgument) TokenLiteral() string {
+	return na.Token.Literal
+}
+
+func (na *NamedArgument) String() string {
+	return na.Name.String()+": "+na.Value.String()
+}
+
+
 // 配列
 
 type ArrayLiteral struct {
@@ -193,11 +371,55 @@ func (a *ArrayLiteral) String() string {
 }
 
 
+// ハッシュ
+
+type HashLiteral struct {
+	Token token.Token
+	// 書いた順に並べる
+	Keys []Expression
+	Values []Expression
+}
+
+func (h *HashLiteral) expressionNode() { }
+func (h *HashLiteral) TokenLiteral() string {
+	return h.Token.Literal
+}
+
+func (h *HashLiteral) String() string {
+	var out bytes.Buffer
+	out.WriteString("{")
+	for i, key := range h.Keys {
+		if i != 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(key.String())
+		out.WriteString(": ")
+		out.WriteString(h.Values[i].String())
+	}
+	out.WriteString("}")
+	return out.String()
+}
+
+
 // 関数リテラル
 
 type FunctionLiteral struct {
 	Token token.Token
 	Arguments []Identifier
+	// Argumentsと同じ長さで、初期値のない引数のところはnil
+	Defaults []Expression
+	// Argumentsと同じ長さで、([a, b]) のように分割して受け取る引数のところだけパターンが入る
+	// そのときのArgumentsの名前はパターンを文字列にしたもの
+	Patterns []Pattern
+	// (a, ...rest) の rest。なければnil
+	Rest *Identifier
+	// (n: int): int { ... } の戻り値の型。なければnil
+	ReturnType *TypeName
+	// 本体の先頭に書いた requires と ensures の条件
+	Requires []Expression
+	Ensures []Expression
+	// f = (...) { ... } のように代入した変数の名前。契約の違反を知らせるときに使う
+	Name string
 	Body []Statement
 }
 
@@ -207,15 +429,47 @@ func (f *FunctionLiteral) TokenLiteral() string {
 }
 
 func (f *FunctionLiteral) String() string {
-	var args []string
-	for _, a := range f.Arguments {
-		args = append(args, a.Name)
-	}
-	var body []string
+	args := ParameterStrings(f.Arguments, f.Defaults, f.Rest)
+	body := ContractStrings(f.Requires, f.Ensures)
 	for _, b := range f.Body {
 		body = append(body, b.String())
 	}
-	return utility.FunctionString(args, body)
+	str := utility.FunctionString(args, body)
+	if f.ReturnType != nil {
+		head := len("("+strings.Join(args, ", ")+")")
+		str = str[:head]+": "+f.ReturnType.String()+str[head:]
+	}
+	return str
+}
+
+
+// (a: int, b = 10, ...rest) の引数をひとつずつ文字列にする
+func ParameterStrings(args []Identifier, defaults []Expression, rest *Identifier) []string {
+	var strs []string
+	for i, a := range args {
+		if i < len(defaults) && defaults[i] != nil {
+			strs = append(strs, a.declarationString()+" = "+defaults[i].String())
+		} else {
+			strs = append(strs, a.declarationString())
+		}
+	}
+	if rest != nil {
+		strs = append(strs, "..."+rest.declarationString())
+	}
+	return strs
+}
+
+
+// 本体の先頭に書く requires n >= 0 と ensures result >= 0
+func ContractStrings(requires []Expression, ensures []Expression) []string {
+	var strs []string
+	for _, r := range requires {
+		strs = append(strs, "requires "+r.String())
+	}
+	for _, e := range ensures {
+		strs = append(strs, "ensures "+e.String())
+	}
+	return strs
 }
 
 
@@ -224,6 +478,8 @@ func (f *FunctionLiteral) String() string {
 type Identifier struct {
 	Token token.Token
 	Name string
+	// 引数と代入の左辺に書いた型の注釈。なければnil。評価するときは使わない
+	Type *TypeName
 }
 
 func (i *Identifier) expressionNode() { }
@@ -235,12 +491,38 @@ func (i *Identifier) String() string {
 	return i.Name
 }
 
+// 型の注釈があれば n: int のように付ける
+func (i *Identifier) declarationString() string {
+	if i.Type != nil {
+		return i.Name+": "+i.Type.String()
+	}
+	return i.Name
+}
+
+
+// 型の注釈 (n: int) の int
+
+type TypeName struct {
+	Token token.Token
+	Name string
+}
+
+func (tn *TypeName) TokenLiteral() string {
+	return tn.Token.Literal
+}
+
+func (tn *TypeName) String() string {
+	return tn.Name
+}
+
 
 // 整数リテラル
 
 type IntegerLiteral struct {
 	Token token.Token
 	Value int64
+	// int64に収まらないときだけ使う(そのときValueは0)
+	BigValue *big.Int
 }
 
 func (il *IntegerLiteral) expressionNode() { }
@@ -252,6 +534,22 @@ func (il *IntegerLiteral) String() string {
 }
 
 
+// 浮動小数点数リテラル
+
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode() { }
+func (fl *FloatLiteral) TokenLiteral() string {
+	return fl.Token.Literal
+}
+func (fl *FloatLiteral) String() string {
+	return fl.Token.Literal
+}
+
+
 // 文字列リテラル
 
 type StringLiteral struct {
@@ -267,3 +565,215 @@ func (sl *StringLiteral) TokenLiteral() string {
 func (sl *StringLiteral) String() string {
 	return utility.Quote(sl.Value)
 }
+
+
+// 構造体の定義 struct(x, y)
+
+type StructLiteral struct {
+	Token token.Token
+	// Point = struct(x, y) のように代入したときの名前。なければ空
+	Name string
+	Fields []Identifier
+}
+
+func (sl *StructLiteral) expressionNode() { }
+func (sl *StructLiteral) TokenLiteral() string {
+	return sl.Token.Literal
+}
+
+func (sl *StructLiteral) String() string {
+	var fields []string
+	for _, f := range sl.Fields {
+		fields = append(fields, f.Name)
+	}
+	return "struct("+strings.Join(fields, ", ")+")"
+}
+
+
+// match式
+
+type MatchExpression struct {
+	Token token.Token
+	Value Expression
+	Arms []MatchArm
+}
+
+type MatchArm struct {
+	Pattern Pattern
+	// なければnil
+	Guard Expression
+	Body Expression
+}
+
+func (me *MatchExpression) expressionNode() { }
+func (me *MatchExpression) TokenLiteral() string {
+	return me.Token.Literal
+}
+
+func (me *MatchExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("match (")
+	out.WriteString(me.Value.String())
+	out.WriteString(") {\n")
+	for _, arm := range me.Arms {
+		out.WriteString("\t")
+		out.WriteString(arm.Pattern.String())
+		if arm.Guard != nil {
+			out.WriteString(" if ")
+			out.WriteString(arm.Guard.String())
+		}
+		out.WriteString(" => ")
+		out.WriteString(strings.Replace(arm.Body.String(), "\n", "\n\t", -1))
+		out.WriteString("\n")
+	}
+	out.WriteString("}")
+	return out.String()
+}
+
+
+// パターン
+
+type Pattern interface {
+	Node
+	patternNode()
+	// パターンが束縛する変数の名前
+	Names() []string
+}
+
+// 1, "a", -2.5, true, null のような値と等しいときに一致する
+type LiteralPattern struct {
+	Token token.Token
+	Value Expression
+}
+
+func (lp *LiteralPattern) patternNode() { }
+func (lp *LiteralPattern) TokenLiteral() string {
+	return lp.Token.Literal
+}
+func (lp *LiteralPattern) String() string {
+	return lp.Value.String()
+}
+func (lp *LiteralPattern) Names() []string {
+	return nil
+}
+
+// _ は何にでも一致して、何も束縛しない
+type WildcardPattern struct {
+	Token token.Token
+}
+
+func (wp *WildcardPattern) patternNode() { }
+func (wp *WildcardPattern) TokenLiteral() string {
+	return wp.Token.Literal
+}
+func (wp *WildcardPattern) String() string {
+	return "_"
+}
+func (wp *WildcardPattern) Names() []string {
+	return nil
+}
+
+// 何にでも一致して、その値を名前に束縛する
+type BindingPattern struct {
+	Name Identifier
+}
+
+func (bp *BindingPattern) patternNode() { }
+func (bp *BindingPattern) TokenLiteral() string {
+	return bp.Name.TokenLiteral()
+}
+func (bp *BindingPattern) String() string {
+	return bp.Name.String()
+}
+func (bp *BindingPattern) Names() []string {
+	return []string{bp.Name.Name}
+}
+
+// [x, y] や [head, ...tail]
+type ArrayPattern struct {
+	Token token.Token
+	Elements []Pattern
+	// ...restがなければnil。..._ なら残りを捨てる
+	Rest Pattern
+}
+
+func (ap *ArrayPattern) patternNode() { }
+func (ap *ArrayPattern) TokenLiteral() string {
+	return ap.Token.Literal
+}
+func (ap *ArrayPattern) String() string {
+	var elements []string
+	for _, e := range ap.Elements {
+		elements = append(elements, e.String())
+	}
+	if ap.Rest != nil {
+		elements = append(elements, "..."+ap.Rest.String())
+	}
+	return "["+strings.Join(elements, ", ")+"]"
+}
+func (ap *ArrayPattern) Names() []string {
+	var names []string
+	for _, e := range ap.Elements {
+		names = append(names, e.Names()...)
+	}
+	if ap.Rest != nil {
+		names = append(names, ap.Rest.Names()...)
+	}
+	return names
+}
+
+// 大文字で始まる名前は構造体や列挙型の値を表す
+// Circle(r) はCircleの値でフィールドがrに一致するとき、Empty はEmptyそのものかEmpty型の値のときに一致する
+type ConstructorPattern struct {
+	Name Identifier
+	// カッコがなければnil
+	Fields []Pattern
+}
+
+func (cp *ConstructorPattern) patternNode() { }
+func (cp *ConstructorPattern) TokenLiteral() string {
+	return cp.Name.TokenLiteral()
+}
+func (cp *ConstructorPattern) String() string {
+	if cp.Fields == nil {
+		return cp.Name.String()
+	}
+	var fields []string
+	for _, f := range cp.Fields {
+		fields = append(fields, f.String())
+	}
+	return cp.Name.String()+"("+strings.Join(fields, ", ")+")"
+}
+func (cp *ConstructorPattern) Names() []string {
+	var names []string
+	for _, f := range cp.Fields {
+		names = append(names, f.Names()...)
+	}
+	return names
+}
+
+// {"k": v} は、キーkがあってその値がvに一致するときに一致する(ほかのキーがあってもよい)
+type HashPattern struct {
+	Token token.Token
+	Keys []Expression
+	Values []Pattern
+}
+
+func (hp *HashPattern) patternNode() { }
+func (hp *HashPattern) TokenLiteral() string {
+	return hp.Token.Literal
+}
+func (hp *HashPattern) String() string {
+	var pairs []string
+	for i, key := range hp.Keys {
+		pairs = append(pairs, key.String()+": "+hp.Values[i].String())
+	}
+	return "{"+strings.Join(pairs, ", ")+"}"
+}
+func (hp *HashPattern) Names() []string {
+	var names []string
+	for _, v := range hp.Values {
+		names = append(names, v.Names()...)
+	}
+	return names
+}
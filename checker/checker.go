@@ -0,0 +1,654 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+
+	"yokan/ast"
+	"yokan/object"
+	"yokan/token"
+)
+
+// 型の注釈を、評価する前に調べる
+// 注釈のない値の型はわからないもの(any)として扱い、わかるところだけを調べる
+
+type Type string
+
+const (
+	Any Type = "any"
+	Int Type = "int"
+	Float Type = "float"
+	// intかfloat
+	Number Type = "number"
+	String Type = "string"
+	Bool Type = "bool"
+	Null Type = "null"
+	Array Type = "array"
+	Hash Type = "hash"
+	Function Type = "function"
+)
+
+var basicTypes = map[string]Type{
+	"any": Any,
+	"int": Int,
+	"float": Float,
+	"number": Number,
+	"string": String,
+	"bool": Bool,
+	"null": Null,
+	"array": Array,
+	"hash": Hash,
+	"function": Function,
+}
+
+// 組み込み関数の宣言の型を、注釈と同じ名前で表す
+var objectTypeNames = map[object.ObjectType]Type{
+	object.INTEGER_OBJ: Int,
+	object.FLOAT_OBJ: Float,
+	object.STRING_OBJ: String,
+	object.BOOLEAN_OBJ: Bool,
+	object.NULL_OBJ: Null,
+	object.ARRAY_OBJ: Array,
+	object.HASH_OBJ: Hash,
+	object.FUNCTION_OBJ: Function,
+	object.BUILDIN_OBJ: Function,
+	object.STRUCT_TYPE_OBJ: Function,
+	object.STRUCT_OBJ: "struct",
+}
+
+// 呼び出しの引数を調べられる関数
+type function struct {
+	name string
+	params []param
+	// 残りの引数を受け取るなら、そのひとつずつの型
+	rest *Type
+	ret Type
+}
+
+type param struct {
+	name string
+	typ Type
+	optional bool
+}
+
+type binding struct {
+	typ Type
+	// 呼び出しを調べられるときだけ入る
+	fn *function
+	sig *object.Signature
+	// 注釈で型を決めた変数には、それ以外の型を代入できない
+	annotated bool
+}
+
+type scope struct {
+	vars map[string]*binding
+	// このスコープで何度も代入される変数は、型が変わるかもしれないのでanyにする
+	assigned map[string]int
+	parent *scope
+}
+
+func newScope(parent *scope) *scope {
+	return &scope{vars: map[string]*binding{ }, assigned: map[string]int{ }, parent: parent}
+}
+
+func (s *scope) get(name string) (*binding, bool) {
+	for sc := s; sc != nil; sc = sc.parent {
+		if b, ok := sc.vars[name]; ok {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+type Checker struct {
+	errors []string
+	// 構造体と列挙型の名前。注釈に使える
+	userTypes map[string]bool
+}
+
+// 見つけた型の誤りを "行:列: メッセージ" の形で返す
+func Check(program *ast.Program) []string {
+	c := &Checker{userTypes: map[string]bool{ }}
+	c.collectUserTypes(program.Statements)
+	c.checkStatements(program.Statements, newRootScope())
+	return c.errors
+}
+
+// 組み込みの変数と関数
+func newRootScope() *scope {
+	root := newScope(nil)
+	for _, sig := range object.BuildinSignatures(object.NewEnvironment()) {
+		root.vars[sig.Name] = &binding{typ: Function, sig: sig}
+	}
+	root.vars["true"] = &binding{typ: Bool}
+	root.vars["false"] = &binding{typ: Bool}
+	root.vars["null"] = &binding{typ: Null}
+	root.vars["PI"] = &binding{typ: Float}
+	root.vars["E"] = &binding{typ: Float}
+	root.vars["inf"] = &binding{typ: Float}
+	root.vars["nan"] = &binding{typ: Float}
+	return newScope(root)
+}
+
+func (c *Checker) errorf(pos token.Position, format string, args ...interface{}) {
+	c.errors = append(c.errors, pos.String()+": "+fmt.Sprintf(format, args...))
+}
+
+// 使う前に宣言されていても注釈に書けるよう、先に構造体と列挙型の名前を集めておく
+func (c *Checker) collectUserTypes(stmts []ast.Statement) {
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *ast.Assign:
+			if sl, ok := stmt.Value.(*ast.StructLiteral); ok {
+				c.userTypes[sl.Name] = true
+			}
+			if fl, ok := stmt.Value.(*ast.FunctionLiteral); ok {
+				c.collectUserTypes(fl.Body)
+			}
+		case *ast.EnumStatement:
+			c.userTypes[stmt.Name.Name] = true
+		}
+	}
+}
+
+func (c *Checker) resolveType(tn *ast.TypeName) Type {
+	if tn == nil {
+		return Any
+	}
+	if t, ok := basicTypes[tn.Name]; ok {
+		return t
+	}
+	if c.userTypes[tn.Name] {
+		return Type(tn.Name)
+	}
+	c.errorf(tn.Token.Pos, "unknown type %s", tn.Name)
+	return Any
+}
+
+// gotの値をwantの型の変数に入れてよいか
+func assignable(got Type, want Type) bool {
+	switch {
+	case got == Any || want == Any || got == want:
+		return true
+	case want == Number:
+		return got == Int || got == Float
+	case got == Number:
+		// numberはintかもしれないしfloatかもしれない
+		return want == Int || want == Float
+	}
+	return false
+}
+
+func isNumeric(t Type) bool {
+	return t == Int || t == Float || t == Number || t == Any
+}
+
+// 最後の文の型を返す
+func (c *Checker) checkStatements(stmts []ast.Statement, sc *scope) Type {
+	for _, stmt := range stmts {
+		if assign, ok := stmt.(*ast.Assign); ok {
+			sc.assigned[assign.Name.Name] += 1
+		}
+	}
+	last := Null
+	for _, stmt := range stmts {
+		last = c.checkStatement(stmt, sc)
+	}
+	return last
+}
+
+func (c *Checker) checkStatement(stmt ast.Statement, sc *scope) Type {
+	switch stmt := stmt.(type) {
+	case *ast.ExpressionStatement:
+		return c.checkExpression(stmt.Expression, sc)
+	case *ast.Assign:
+		c.checkAssign(stmt, sc)
+	case *ast.DestructuringAssign:
+		c.checkExpression(stmt.Value, sc)
+		for _, name := range stmt.Pattern.Names() {
+			sc.vars[name] = &binding{typ: Any}
+		}
+	case *ast.IndexAssign:
+		c.checkExpression(stmt.Target, sc)
+		c.checkExpression(stmt.Value, sc)
+	case *ast.MemberAssign:
+		c.checkExpression(stmt.Target.Object, sc)
+		c.checkExpression(stmt.Value, sc)
+	case *ast.CompoundAssign:
+		c.checkCompoundAssign(stmt, sc)
+	case *ast.EnumStatement:
+		c.checkEnumStatement(stmt, sc)
+	}
+	// 代入の文は値を持たない
+	return Any
+}
+
+func (c *Checker) checkAssign(assign *ast.Assign, sc *scope) {
+	name := assign.Name.Name
+	declared := Any
+	annotated := assign.Name.Type != nil
+	if annotated {
+		declared = c.resolveType(assign.Name.Type)
+	} else if b, ok := sc.vars[name]; ok && b.annotated {
+		// 前に注釈を付けた変数への代入
+		declared = b.typ
+		annotated = true
+	}
+
+	b := &binding{typ: declared, annotated: annotated}
+	var got Type
+	switch value := assign.Value.(type) {
+	case *ast.FunctionLiteral:
+		// 再帰呼び出しを調べられるよう、本体より先に束縛する
+		b.fn = c.functionOf(value, name)
+		if !annotated {
+			b.typ = Function
+		}
+		sc.vars[name] = b
+		c.checkFunctionBody(value, b.fn, sc)
+		got = Function
+	case *ast.StructLiteral:
+		b.fn = c.constructorOf(value.Name, value.Fields, Type(value.Name))
+		got = c.checkExpression(value, sc)
+	default:
+		got = c.checkExpression(value, sc)
+	}
+
+	if annotated && !assignable(got, declared) {
+		c.errorf(assign.Name.Token.Pos, "%s is declared as %s but got %s", name, declared, got)
+	}
+	if !annotated {
+		b.typ = got
+		if sc.assigned[name] > 1 {
+			b.typ = Any
+			b.fn = nil
+		}
+	}
+	sc.vars[name] = b
+}
+
+func (c *Checker) checkCompoundAssign(ca *ast.CompoundAssign, sc *scope) {
+	current := c.checkExpression(ca.Target, sc)
+	value := c.checkExpression(ca.Value, sc)
+	result := c.checkArithmetic(ca.Operator, current, value, ca.Token.Pos)
+	ident, ok := ca.Target.(*ast.Identifier)
+	if !ok {
+		return
+	}
+	if b, ok := sc.vars[ident.Name]; ok && b.annotated && !assignable(result, b.typ) {
+		c.errorf(ident.Token.Pos, "%s is declared as %s but got %s", ident.Name, b.typ, result)
+	}
+}
+
+func (c *Checker) checkEnumStatement(es *ast.EnumStatement, sc *scope) {
+	enum := Type(es.Name.Name)
+	for _, v := range es.Variants {
+		if v.Fields == nil {
+			sc.vars[v.Name.Name] = &binding{typ: enum}
+			continue
+		}
+		sc.vars[v.Name.Name] = &binding{typ: Function, fn: c.constructorOf(v.Name.Name, v.Fields, enum)}
+	}
+	sc.vars[es.Name.Name] = &binding{typ: Any}
+}
+
+// 構造体や列挙型の値を作る関数
+func (c *Checker) constructorOf(name string, fields []ast.Identifier, ret Type) *function {
+	fn := &function{name: name, ret: ret}
+	for _, f := range fields {
+		fn.params = append(fn.params, param{name: f.Name, typ: Any})
+	}
+	return fn
+}
+
+func (c *Checker) functionOf(fl *ast.FunctionLiteral, name string) *function {
+	fn := &function{name: name, ret: c.resolveType(fl.ReturnType)}
+	for i, arg := range fl.Arguments {
+		optional := i < len(fl.Defaults) && fl.Defaults[i] != nil
+		fn.params = append(fn.params, param{name: arg.Name, typ: c.resolveType(arg.Type), optional: optional})
+	}
+	if fl.Rest != nil {
+		rest := c.resolveType(fl.Rest.Type)
+		fn.rest = &rest
+	}
+	return fn
+}
+
+func (c *Checker) checkFunctionBody(fl *ast.FunctionLiteral, fn *function, sc *scope) {
+	inner := newScope(sc)
+	for i, arg := range fl.Arguments {
+		if i < len(fl.Defaults) && fl.Defaults[i] != nil {
+			got := c.checkExpression(fl.Defaults[i], inner)
+			if !assignable(got, fn.params[i].typ) {
+				c.errorf(arg.Token.Pos, "default value of %s is declared as %s but got %s", arg.Name, fn.params[i].typ, got)
+			}
+		}
+		if i < len(fl.Patterns) && fl.Patterns[i] != nil {
+			for _, name := range fl.Patterns[i].Names() {
+				inner.vars[name] = &binding{typ: Any}
+			}
+			continue
+		}
+		inner.vars[arg.Name] = &binding{typ: fn.params[i].typ, annotated: arg.Type != nil}
+	}
+	if fl.Rest != nil {
+		inner.vars[fl.Rest.Name] = &binding{typ: Array}
+	}
+	inner.vars["self"] = &binding{typ: Any}
+
+	c.checkContracts("requires", fl.Requires, inner)
+	got := c.checkStatements(fl.Body, inner)
+	if fl.ReturnType != nil && !assignable(got, fn.ret) {
+		c.errorf(fl.ReturnType.Token.Pos, "%s should return %s but returns %s", functionName(fn), fn.ret, got)
+	}
+	if len(fl.Ensures) != 0 {
+		result := newScope(inner)
+		result.vars["result"] = &binding{typ: got}
+		c.checkContracts("ensures", fl.Ensures, result)
+	}
+}
+
+// 条件はboolでなければならない
+func (c *Checker) checkContracts(clause string, conditions []ast.Expression, sc *scope) {
+	for _, cond := range conditions {
+		if t := c.checkExpression(cond, sc); !assignable(t, Bool) {
+			c.errorf(position(cond), "%s expects bool but got %s", clause, t)
+		}
+	}
+}
+
+func functionName(fn *function) string {
+	if fn.name == "" {
+		return "function"
+	}
+	return fn.name
+}
+
+func (c *Checker) checkExpression(expr ast.Expression, sc *scope) Type {
+	switch expr := expr.(type) {
+	case *ast.IntegerLiteral:
+		return Int
+	case *ast.FloatLiteral:
+		return Float
+	case *ast.StringLiteral:
+		return String
+	case *ast.ArrayLiteral:
+		for _, e := range expr.Value {
+			c.checkExpression(e, sc)
+		}
+		return Array
+	case *ast.HashLiteral:
+		for i, key := range expr.Keys {
+			c.checkExpression(key, sc)
+			c.checkExpression(expr.Values[i], sc)
+		}
+		return Hash
+	case *ast.Identifier:
+		if b, ok := sc.get(expr.Name); ok {
+			return b.typ
+		}
+		return Any
+	case *ast.FunctionLiteral:
+		c.checkFunctionBody(expr, c.functionOf(expr, ""), sc)
+		return Function
+	case *ast.StructLiteral:
+		return Function
+	case *ast.PrefixExpression:
+		right := c.checkExpression(expr.Right, sc)
+		if !isNumeric(right) {
+			c.errorf(expr.Token.Pos, "operator %s expects number but got %s", expr.Operator, right)
+			return Any
+		}
+		return right
+	case *ast.InfixExpression:
+		left := c.checkExpression(expr.Left, sc)
+		right := c.checkExpression(expr.Right, sc)
+		switch expr.Operator {
+		case "+", "-", "*", "/", "%":
+			return c.checkArithmetic(expr.Operator, left, right, expr.Token.Pos)
+		}
+		return Bool
+	case *ast.FunctionCalling:
+		return c.checkCall(expr, sc)
+	case *ast.IndexExpression:
+		left := c.checkExpression(expr.Left, sc)
+		c.checkExpression(expr.Index, sc)
+		switch left {
+		case String:
+			return String
+		case Any, Array, Hash:
+			return Any
+		}
+		c.errorf(expr.Token.Pos, "%s is not indexable", left)
+		return Any
+	case *ast.MemberExpression:
+		c.checkExpression(expr.Object, sc)
+		return Any
+	case *ast.MatchExpression:
+		return c.checkMatch(expr, sc)
+	case *ast.NamedArgument:
+		return c.checkExpression(expr.Value, sc)
+	}
+	return Any
+}
+
+// 四則演算と余り。どちらも整数なら整数、どちらかが浮動小数点数なら浮動小数点数になる
+func (c *Checker) checkArithmetic(operator string, left Type, right Type, pos token.Position) Type {
+	for _, t := range []Type{left, right} {
+		if !isNumeric(t) {
+			c.errorf(pos, "operator %s expects number but got %s", operator, t)
+			return Any
+		}
+	}
+	switch {
+	case left == Any || right == Any:
+		return Any
+	case left == Int && right == Int:
+		return Int
+	case left == Float || right == Float:
+		return Float
+	}
+	return Number
+}
+
+// 腕の型がすべて同じならその型
+func (c *Checker) checkMatch(me *ast.MatchExpression, sc *scope) Type {
+	c.checkExpression(me.Value, sc)
+	var result Type
+	for i, arm := range me.Arms {
+		inner := newScope(sc)
+		for _, name := range arm.Pattern.Names() {
+			inner.vars[name] = &binding{typ: Any}
+		}
+		if arm.Guard != nil {
+			c.checkExpression(arm.Guard, inner)
+		}
+		t := c.checkExpression(arm.Body, inner)
+		if i == 0 {
+			result = t
+		} else if result != t {
+			result = Any
+		}
+	}
+	if result == "" {
+		return Any
+	}
+	return result
+}
+
+func (c *Checker) checkCall(fc *ast.FunctionCalling, sc *scope) Type {
+	var callee *binding
+	var calleeType Type
+	if ident, ok := fc.Function.(*ast.Identifier); ok {
+		callee, _ = sc.get(ident.Name)
+		calleeType = c.checkExpression(ident, sc)
+	} else {
+		calleeType = c.checkExpression(fc.Function, sc)
+	}
+
+	var args []Type
+	var named []*ast.NamedArgument
+	var namedTypes []Type
+	for _, arg := range fc.Arguments {
+		t := c.checkExpression(arg, sc)
+		if na, ok := arg.(*ast.NamedArgument); ok {
+			named = append(named, na)
+			namedTypes = append(namedTypes, t)
+			continue
+		}
+		args = append(args, t)
+	}
+
+	if calleeType != Any && calleeType != Function {
+		c.errorf(position(fc.Function), "%s is %s, not a function", fc.Function.String(), calleeType)
+		return Any
+	}
+	if callee == nil {
+		return Any
+	}
+	switch {
+	case callee.fn != nil:
+		c.checkArguments(fc, callee.fn, args, named, namedTypes)
+		return callee.fn.ret
+	case callee.sig != nil:
+		c.checkArguments(fc, functionOfSignature(callee.sig), args, named, namedTypes)
+	}
+	return Any
+}
+
+// 組み込み関数の宣言を、注釈と同じ形にする
+func functionOfSignature(sig *object.Signature) *function {
+	fn := &function{name: sig.Name, ret: Any}
+	for _, p := range sig.Params {
+		t := typeOfObjectTypes(p.Types)
+		if p.Variadic {
+			fn.rest = &t
+			break
+		}
+		fn.params = append(fn.params, param{name: p.Name, typ: t, optional: p.Optional})
+	}
+	return fn
+}
+
+// 組み込み関数の引数の型はいくつか並ぶことがあるので、"string|array" のような名前にする
+func typeOfObjectTypes(types []object.ObjectType) Type {
+	if len(types) == 0 {
+		return Any
+	}
+	var names []string
+	seen := map[Type]bool{ }
+	for _, t := range types {
+		name := objectTypeNames[t]
+		if !seen[name] {
+			names = append(names, string(name))
+			seen[name] = true
+		}
+	}
+	if len(names) == 2 && seen[Int] && seen[Float] {
+		return Number
+	}
+	return Type(strings.Join(names, "|"))
+}
+
+// "string|array" のような型にも入れてよいか
+func acceptable(got Type, want Type) bool {
+	if got == "" {
+		return true
+	}
+	for _, w := range strings.Split(string(want), "|") {
+		if assignable(got, Type(w)) {
+			return true
+		}
+		if w == "struct" && got != Any && basicTypes[string(got)] == "" {
+			// 構造体と列挙型の値
+			return true
+		}
+		if w == string(Number) && got == Number {
+			return true
+		}
+	}
+	// numberは、intとfloatの両方を受け取れるときだけ通す
+	if got == Number {
+		return strings.Contains(string(want), string(Int)) && strings.Contains(string(want), string(Float))
+	}
+	return false
+}
+
+func (c *Checker) checkArguments(fc *ast.FunctionCalling, fn *function, args []Type, named []*ast.NamedArgument, namedTypes []Type) {
+	name := functionName(fn)
+	min := 0
+	for _, p := range fn.params {
+		if !p.optional {
+			min += 1
+		}
+	}
+	max := len(fn.params)
+	if fn.rest != nil {
+		max = -1
+	}
+	if count := len(args)+len(named); count < min || max >= 0 && count > max {
+		c.errorf(fc.Token.Pos, "%s expects %s arguments but got %d", name, object.ArityString(min, max), count)
+		return
+	}
+
+	positional := 0
+	for _, e := range fc.Arguments {
+		if _, ok := e.(*ast.NamedArgument); ok {
+			continue
+		}
+		t := args[positional]
+		if positional < len(fn.params) {
+			p := fn.params[positional]
+			if !acceptable(t, p.typ) {
+				c.errorf(position(e), "argument %s of %s expects %s but got %s", p.name, name, p.typ, t)
+			}
+		} else if fn.rest != nil && !acceptable(t, *fn.rest) {
+			c.errorf(position(e), "arguments of %s expects %s but got %s", name, *fn.rest, t)
+		}
+		positional += 1
+	}
+	for i, na := range named {
+		for _, p := range fn.params {
+			if p.name == na.Name.Name && !acceptable(namedTypes[i], p.typ) {
+				c.errorf(na.Token.Pos, "argument %s of %s expects %s but got %s", p.name, name, p.typ, namedTypes[i])
+			}
+		}
+	}
+}
+
+// 式の先頭の位置
+func position(expr ast.Expression) token.Position {
+	switch expr := expr.(type) {
+	case *ast.Identifier:
+		return expr.Token.Pos
+	case *ast.IntegerLiteral:
+		return expr.Token.Pos
+	case *ast.FloatLiteral:
+		return expr.Token.Pos
+	case *ast.StringLiteral:
+		return expr.Token.Pos
+	case *ast.ArrayLiteral:
+		return expr.Token.Pos
+	case *ast.HashLiteral:
+		return expr.Token.Pos
+	case *ast.FunctionLiteral:
+		return expr.Token.Pos
+	case *ast.StructLiteral:
+		return expr.Token.Pos
+	case *ast.MatchExpression:
+		return expr.Token.Pos
+	case *ast.PrefixExpression:
+		return expr.Token.Pos
+	case *ast.InfixExpression:
+		return position(expr.Left)
+	case *ast.FunctionCalling:
+		return position(expr.Function)
+	case *ast.IndexExpression:
+		return position(expr.Left)
+	case *ast.MemberExpression:
+		return position(expr.Object)
+	case *ast.NamedArgument:
+		return expr.Token.Pos
+	}
+	return token.Position{ }
+}
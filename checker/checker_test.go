@@ -0,0 +1,74 @@
+package checker
+
+import (
+	"testing"
+)
+
+func TestCheckWithoutErrors(t *testing.T) {
+	inputs := []string {
+		"x = 1\n x + 2.5",
+		"x: int = 1 + 2",
+		"x: number = 1.5\n x = 2",
+		"x: float = 1.5 * 2",
+		"x: float = nan\n y: float = -inf",
+		"x: any = \"a\"",
+		"f = (a, b) { a + b }\n f(\"a\", [])",
+		"f = (n: int): int { n * 2 }\n y: int = f(3)",
+		"fact = (n: int): int { if(n <= 1, 1, n * fact(n - 1)) }",
+		"f = (s: string = \"a\") { s }\n f()\n f(s: \"b\")",
+		"f = (...xs: int) { xs }\n f(1, 2, 3)",
+		"len(\"abc\")\n len([1, 2])",
+		"Point = struct(x, y)\n p: Point = Point(1, 2)",
+		"enum Shape { Circle(r), Empty }\n s: Shape = Circle(1)\n e: Shape = Empty",
+		"x = 1\n x = \"a\"\n len(x)",
+		"f = (x: int) { x }\n g = f\n g(1)",
+		"n: int = match (1) { 1 => 2, _ => 3 }",
+		"s: string = \"abc\"[0]",
+	}
+	for _, input := range inputs {
+		errors := check(t, input)
+		if len(errors) != 0 {
+			t.Errorf("%s: checker has errors %q", input, errors)
+		}
+	}
+}
+
+func TestCheckErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{"x: int = \"a\"", "1:1: x is declared as int but got string"},
+		{"x: int = 1.5", "1:1: x is declared as int but got float"},
+		{"x: int = 1\n x = \"a\"", "2:2: x is declared as int but got string"},
+		{"x: int = 1\n x /= 2.0", "2:2: x is declared as int but got float"},
+		{"x: Point = 1", "1:4: unknown type Point"},
+		{"1 + \"a\"", "1:3: operator + expects number but got string"},
+		{"-[1]", "1:1: operator - expects number but got array"},
+		{"f = (n: int) { n }\n f(\"a\")", "2:4: argument n of f expects int but got string"},
+		{"f = (n: int) { n }\n f(n: \"a\")", "2:4: argument n of f expects int but got string"},
+		{"f = (n: int) { n }\n f(1, 2)", "2:3: f expects 1 arguments but got 2"},
+		{"f = (n: int = \"a\") { n }", "1:6: default value of n is declared as int but got string"},
+		{"f = (n: int): string { n + 1 }", "1:15: f should return string but returns int"},
+		{"f = (): string { 1 }", "1:9: f should return string but returns int"},
+		{"f = (): int { 1 }\n s: string = f()", "2:2: s is declared as string but got int"},
+		{"f = (...xs: int) { xs }\n f(1, \"a\")", "2:7: arguments of f expects int but got string"},
+		{"len(1)", "1:5: argument value of len expects string|array but got int"},
+		{"x = 1\n x(2)", "2:2: x is int, not a function"},
+		{"\"a\"()", "1:1: \"a\" is string, not a function"},
+		{"Point = struct(x, y)\n Point(1, 2, 3)", "2:7: Point expects 2 arguments but got 3"},
+		{"enum Shape { Empty }\n Empty(1)", "2:2: Empty is Shape, not a function"},
+		{"1[0]", "1:2: int is not indexable"},
+		{"f = (n: int) {\n requires n + 1\n n\n }", "2:11: requires expects bool but got int"},
+	}
+	for _, tt := range tests {
+		errors := check(t, tt.input)
+		if len(errors) != 1 || errors[0] != tt.expected {
+			t.Errorf("%s: errors is not [%q]. got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+
+func check(t *testing.T, input string) []string {
+	return Check(parse(t, input))
+}
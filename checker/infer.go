@@ -0,0 +1,781 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+
+	"yokan/ast"
+	"yokan/object"
+	"yokan/token"
+)
+
+// 注釈のないプログラムの型を Hindley-Milner の方法で推論する
+// 配列とハッシュの要素や組み込み関数の戻り値のように決められないものは any にして、何とでも一致させる
+// intとfloatは混ぜて計算できるので、互いに一致するものとして扱う。ただし注釈で書いた型は区別する
+
+type term interface {
+	term()
+}
+
+// 型変数。instanceが決まるまでは何の型かわからない
+type tvar struct {
+	id int
+	instance term
+	// 計算に使われたので、intかfloatのはず
+	numeric bool
+}
+
+type tcon struct {
+	name string
+	// 注釈で書いたintとfloatは、互いに入れ替えられない
+	strict bool
+}
+
+type tfunc struct {
+	params []term
+	// 名前付きで渡す引数のため。わからなければ空
+	names []string
+	// 初期値のない引数の数
+	required int
+	// 残りの引数を受け取る
+	rest bool
+	ret term
+}
+
+func (t *tvar) term() { }
+func (t *tcon) term() { }
+func (t *tfunc) term() { }
+
+// 代入した関数を、呼ぶたびに型変数を取り替えて使えるようにしたもの
+type scheme struct {
+	vars []*tvar
+	t term
+}
+
+var (
+	anyType = &tcon{name: string(Any)}
+	intType = &tcon{name: string(Int)}
+	floatType = &tcon{name: string(Float)}
+	stringType = &tcon{name: string(String)}
+	boolType = &tcon{name: string(Bool)}
+	nullType = &tcon{name: string(Null)}
+	arrayType = &tcon{name: string(Array)}
+	hashType = &tcon{name: string(Hash)}
+)
+
+type typeScope struct {
+	vars map[string]*scheme
+	parent *typeScope
+}
+
+func newTypeScope(parent *typeScope) *typeScope {
+	return &typeScope{vars: map[string]*scheme{ }, parent: parent}
+}
+
+func (s *typeScope) get(name string) (*scheme, bool) {
+	for sc := s; sc != nil; sc = sc.parent {
+		if t, ok := sc.vars[name]; ok {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+func (s *typeScope) set(name string, t term) {
+	s.vars[name] = &scheme{t: t}
+}
+
+type Inferer struct {
+	errors []string
+	scope *typeScope
+	nextID int
+	// 構造体と列挙型の名前。注釈に使える
+	userTypes map[string]bool
+}
+
+// REPLのように、前に推論した束縛を覚えたまま続けて推論できる
+func NewInferer() *Inferer {
+	inf := &Inferer{userTypes: map[string]bool{ }}
+	root := newTypeScope(nil)
+	for _, sig := range object.BuildinSignatures(object.NewEnvironment()) {
+		root.set(sig.Name, signatureType(sig))
+	}
+	root.set("true", boolType)
+	root.set("false", boolType)
+	root.set("null", nullType)
+	root.set("PI", floatType)
+	root.set("E", floatType)
+	root.set("inf", floatType)
+	root.set("nan", floatType)
+	inf.scope = newTypeScope(root)
+	return inf
+}
+
+// プログラム全体の型を推論し、見つけた型の誤りを "行:列: メッセージ" の形で返す
+func Infer(program *ast.Program) []string {
+	return NewInferer().Program(program)
+}
+
+// 束縛を覚えておき、このプログラムで見つけた誤りを返す
+func (inf *Inferer) Program(program *ast.Program) []string {
+	inf.errors = nil
+	inf.collectUserTypes(program.Statements)
+	inf.inferStatements(program.Statements, inf.scope)
+	return inf.errors
+}
+
+// 最後の式の型を "int -> int -> int" のような形で返す。束縛は覚えない
+func (inf *Inferer) TypeOf(program *ast.Program) (string, []string) {
+	inf.errors = nil
+	scope := newTypeScope(inf.scope)
+	t := inf.inferStatements(program.Statements, scope)
+	// 代入なら、代入した変数の型
+	if n := len(program.Statements); n != 0 {
+		if assign, ok := program.Statements[n-1].(*ast.Assign); ok {
+			return typeString(scope.vars[assign.Name.Name].t), inf.errors
+		}
+	}
+	return typeString(inf.generalize(t, inf.scope).t), inf.errors
+}
+
+func (inf *Inferer) errorf(pos token.Position, format string, args ...interface{}) {
+	inf.errors = append(inf.errors, pos.String()+": "+fmt.Sprintf(format, args...))
+}
+
+func (inf *Inferer) collectUserTypes(stmts []ast.Statement) {
+	c := &Checker{userTypes: inf.userTypes}
+	c.collectUserTypes(stmts)
+}
+
+func (inf *Inferer) fresh() *tvar {
+	inf.nextID += 1
+	return &tvar{id: inf.nextID}
+}
+
+// 組み込み関数の引数の型は、ひとつに決まるときだけ使う
+func signatureType(sig *object.Signature) term {
+	fn := &tfunc{ret: anyType}
+	for _, p := range sig.Params {
+		if p.Variadic {
+			fn.rest = true
+			break
+		}
+		t := term(anyType)
+		if name := typeOfObjectTypes(p.Types); name != Any && name != Number && name != "struct" && !strings.Contains(string(name), "|") {
+			t = &tcon{name: string(name)}
+		}
+		fn.params = append(fn.params, t)
+		fn.names = append(fn.names, p.Name)
+		if !p.Optional {
+			fn.required += 1
+		}
+	}
+	return fn
+}
+
+// 注釈の型。numberはintかfloatのどちらか
+func (inf *Inferer) annotationType(tn *ast.TypeName) term {
+	if tn == nil {
+		return inf.fresh()
+	}
+	switch name := tn.Name; {
+	case name == string(Number):
+		v := inf.fresh()
+		v.numeric = true
+		return v
+	case name == string(Any):
+		return anyType
+	case basicTypes[name] != "" || inf.userTypes[name]:
+		return &tcon{name: name, strict: true}
+	}
+	inf.errorf(tn.Token.Pos, "unknown type %s", tn.Name)
+	return anyType
+}
+
+// 決まった型変数をたどる
+func prune(t term) term {
+	if v, ok := t.(*tvar); ok && v.instance != nil {
+		v.instance = prune(v.instance)
+		return v.instance
+	}
+	return t
+}
+
+func occurs(v *tvar, t term) bool {
+	switch t := prune(t).(type) {
+	case *tvar:
+		return t == v
+	case *tfunc:
+		for _, p := range t.params {
+			if occurs(v, p) {
+				return true
+			}
+		}
+		return occurs(v, t.ret)
+	}
+	return false
+}
+
+func isNumericCon(c *tcon) bool {
+	return c.name == string(Int) || c.name == string(Float) || c.name == string(Any)
+}
+
+func (inf *Inferer) unify(a term, b term) bool {
+	a = prune(a)
+	b = prune(b)
+	if v, ok := a.(*tvar); ok {
+		if a == b {
+			return true
+		}
+		if occurs(v, b) {
+			return false
+		}
+		switch b := b.(type) {
+		case *tvar:
+			b.numeric = b.numeric || v.numeric
+		case *tcon:
+			if v.numeric && !isNumericCon(b) {
+				return false
+			}
+		case *tfunc:
+			if v.numeric {
+				return false
+			}
+		}
+		v.instance = b
+		return true
+	}
+	if _, ok := b.(*tvar); ok {
+		return inf.unify(b, a)
+	}
+	if c, ok := a.(*tcon); ok && c.name == string(Any) {
+		return true
+	}
+	if c, ok := b.(*tcon); ok && c.name == string(Any) {
+		return true
+	}
+	switch a := a.(type) {
+	case *tcon:
+		switch b := b.(type) {
+		case *tcon:
+			return a.name == b.name || isNumericCon(a) && isNumericCon(b) && !a.strict && !b.strict
+		case *tfunc:
+			// 注釈や組み込み関数のfunctionは、どんな関数とも一致する
+			return a.name == string(Function)
+		}
+		return false
+	case *tfunc:
+		if c, ok := b.(*tcon); ok {
+			return inf.unify(c, a)
+		}
+		b, ok := b.(*tfunc)
+		if !ok || len(a.params) != len(b.params) || a.rest != b.rest {
+			return false
+		}
+		for i := range a.params {
+			if !inf.unify(a.params[i], b.params[i]) {
+				return false
+			}
+		}
+		return inf.unify(a.ret, b.ret)
+	}
+	return false
+}
+
+// envに現れない型変数を、呼ぶたびに取り替えられるようにする
+// 計算に使われただけで決まらなかった型変数はintにする
+func (inf *Inferer) generalize(t term, env *typeScope) *scheme {
+	bound := map[*tvar]bool{ }
+	for sc := env; sc != nil; sc = sc.parent {
+		for _, s := range sc.vars {
+			quantified := map[*tvar]bool{ }
+			for _, v := range s.vars {
+				quantified[v] = true
+			}
+			for _, v := range freeVars(s.t) {
+				if !quantified[v] {
+					bound[v] = true
+				}
+			}
+		}
+	}
+	s := &scheme{t: t}
+	for _, v := range freeVars(t) {
+		if bound[v] {
+			continue
+		}
+		if v.numeric {
+			v.instance = intType
+			continue
+		}
+		s.vars = append(s.vars, v)
+	}
+	return s
+}
+
+func freeVars(t term) []*tvar {
+	var vars []*tvar
+	seen := map[*tvar]bool{ }
+	var walk func(t term)
+	walk = func(t term) {
+		switch t := prune(t).(type) {
+		case *tvar:
+			if !seen[t] {
+				seen[t] = true
+				vars = append(vars, t)
+			}
+		case *tfunc:
+			for _, p := range t.params {
+				walk(p)
+			}
+			walk(t.ret)
+		}
+	}
+	walk(t)
+	return vars
+}
+
+func (inf *Inferer) instantiate(s *scheme) term {
+	if len(s.vars) == 0 {
+		return s.t
+	}
+	mapping := map[*tvar]term{ }
+	for _, v := range s.vars {
+		mapping[v] = inf.fresh()
+	}
+	var copy func(t term) term
+	copy = func(t term) term {
+		switch t := prune(t).(type) {
+		case *tvar:
+			if m, ok := mapping[t]; ok {
+				return m
+			}
+			return t
+		case *tfunc:
+			fn := &tfunc{names: t.names, required: t.required, rest: t.rest, ret: copy(t.ret)}
+			for _, p := range t.params {
+				fn.params = append(fn.params, copy(p))
+			}
+			return fn
+		}
+		return t
+	}
+	return copy(s.t)
+}
+
+// 型変数はa, b, c...と名前を付けて表す
+func typeString(t term) string {
+	names := map[*tvar]string{ }
+	var str func(t term, inParam bool) string
+	str = func(t term, inParam bool) string {
+		switch t := prune(t).(type) {
+		case *tvar:
+			if _, ok := names[t]; !ok {
+				names[t] = string(rune('a'+len(names)%26)) + strings.Repeat("'", len(names)/26)
+			}
+			return names[t]
+		case *tcon:
+			return t.name
+		case *tfunc:
+			var parts []string
+			for _, p := range t.params {
+				parts = append(parts, str(p, true))
+			}
+			if t.rest {
+				parts = append(parts, "...any")
+			}
+			if len(parts) == 0 {
+				parts = append(parts, "()")
+			}
+			s := strings.Join(parts, " -> ") + " -> " + str(t.ret, false)
+			if inParam {
+				return "(" + s + ")"
+			}
+			return s
+		}
+		return "?"
+	}
+	return str(t, false)
+}
+
+func (inf *Inferer) inferStatements(stmts []ast.Statement, sc *typeScope) term {
+	var last term = nullType
+	for _, stmt := range stmts {
+		last = inf.inferStatement(stmt, sc)
+	}
+	return last
+}
+
+func (inf *Inferer) inferStatement(stmt ast.Statement, sc *typeScope) term {
+	switch stmt := stmt.(type) {
+	case *ast.ExpressionStatement:
+		return inf.inferExpression(stmt.Expression, sc)
+	case *ast.Assign:
+		inf.inferAssign(stmt, sc)
+	case *ast.DestructuringAssign:
+		inf.inferExpression(stmt.Value, sc)
+		for _, name := range stmt.Pattern.Names() {
+			sc.set(name, anyType)
+		}
+	case *ast.IndexAssign:
+		inf.inferExpression(stmt.Target, sc)
+		inf.inferExpression(stmt.Value, sc)
+	case *ast.MemberAssign:
+		inf.inferExpression(stmt.Target.Object, sc)
+		inf.inferExpression(stmt.Value, sc)
+	case *ast.CompoundAssign:
+		current := inf.inferExpression(stmt.Target, sc)
+		value := inf.inferExpression(stmt.Value, sc)
+		result := inf.inferArithmetic(stmt.Operator, current, value, stmt.Token.Pos)
+		if ident, ok := stmt.Target.(*ast.Identifier); ok {
+			sc.set(ident.Name, result)
+		}
+	case *ast.EnumStatement:
+		enum := &tcon{name: stmt.Name.Name}
+		for _, v := range stmt.Variants {
+			if v.Fields == nil {
+				sc.set(v.Name.Name, enum)
+				continue
+			}
+			sc.set(v.Name.Name, constructorType(v.Fields, enum))
+		}
+		sc.set(stmt.Name.Name, anyType)
+	}
+	// 代入の文は値を持たない
+	return anyType
+}
+
+// フィールドには何でも入れられる
+func constructorType(fields []ast.Identifier, ret term) *tfunc {
+	fn := &tfunc{ret: ret, required: len(fields)}
+	for _, f := range fields {
+		fn.params = append(fn.params, anyType)
+		fn.names = append(fn.names, f.Name)
+	}
+	return fn
+}
+
+func (inf *Inferer) inferAssign(assign *ast.Assign, sc *typeScope) {
+	name := assign.Name.Name
+	var t term
+	switch value := assign.Value.(type) {
+	case *ast.FunctionLiteral:
+		// 再帰呼び出しでは、推論している途中の型をそのまま使う
+		self := inf.fresh()
+		sc.set(name, self)
+		t = inf.inferFunction(value, name, sc)
+		if !inf.unify(self, t) {
+			inf.errorf(value.Token.Pos, "%s is used as %s but is %s", name, typeString(self), typeString(t))
+		}
+	case *ast.StructLiteral:
+		t = constructorType(value.Fields, &tcon{name: value.Name})
+	default:
+		t = inf.inferExpression(value, sc)
+	}
+	if assign.Name.Type != nil {
+		declared := inf.annotationType(assign.Name.Type)
+		if !inf.unify(declared, t) {
+			inf.errorf(assign.Name.Token.Pos, "%s is declared as %s but got %s", name, typeString(declared), typeString(t))
+		}
+	}
+	// 再帰呼び出しのために置いた束縛は、取り替えられる型変数を決めるときには含めない
+	delete(sc.vars, name)
+	sc.vars[name] = inf.generalize(t, sc)
+}
+
+func (inf *Inferer) inferFunction(fl *ast.FunctionLiteral, name string, sc *typeScope) term {
+	inner := newTypeScope(sc)
+	fn := &tfunc{}
+	for i, arg := range fl.Arguments {
+		t := inf.annotationType(arg.Type)
+		fn.params = append(fn.params, t)
+		fn.names = append(fn.names, arg.Name)
+		if i < len(fl.Defaults) && fl.Defaults[i] != nil {
+			got := inf.inferExpression(fl.Defaults[i], inner)
+			if !inf.unify(t, got) {
+				inf.errorf(arg.Token.Pos, "default value of %s is declared as %s but got %s", arg.Name, typeString(t), typeString(got))
+			}
+		} else {
+			fn.required += 1
+		}
+		if i < len(fl.Patterns) && fl.Patterns[i] != nil {
+			for _, name := range fl.Patterns[i].Names() {
+				inner.set(name, anyType)
+			}
+			continue
+		}
+		inner.set(arg.Name, t)
+	}
+	if fl.Rest != nil {
+		fn.rest = true
+		inner.set(fl.Rest.Name, arrayType)
+	}
+	inner.set("self", anyType)
+
+	inf.inferContracts("requires", fl.Requires, inner)
+	fn.ret = inf.inferStatements(fl.Body, inner)
+	if len(fl.Ensures) != 0 {
+		result := newTypeScope(inner)
+		result.set("result", fn.ret)
+		inf.inferContracts("ensures", fl.Ensures, result)
+	}
+	if fl.ReturnType != nil {
+		declared := inf.annotationType(fl.ReturnType)
+		if !inf.unify(declared, fn.ret) {
+			if name == "" {
+				name = "function"
+			}
+			inf.errorf(fl.ReturnType.Token.Pos, "%s should return %s but returns %s", name, typeString(declared), typeString(fn.ret))
+		}
+	}
+	return fn
+}
+
+func (inf *Inferer) inferContracts(clause string, conditions []ast.Expression, sc *typeScope) {
+	for _, cond := range conditions {
+		if t := inf.inferExpression(cond, sc); !inf.unify(boolType, t) {
+			inf.errorf(position(cond), "%s expects bool but got %s", clause, typeString(t))
+		}
+	}
+}
+
+func (inf *Inferer) inferExpression(expr ast.Expression, sc *typeScope) term {
+	switch expr := expr.(type) {
+	case *ast.IntegerLiteral:
+		return intType
+	case *ast.FloatLiteral:
+		return floatType
+	case *ast.StringLiteral:
+		return stringType
+	case *ast.ArrayLiteral:
+		for _, e := range expr.Value {
+			inf.inferExpression(e, sc)
+		}
+		return arrayType
+	case *ast.HashLiteral:
+		for i, key := range expr.Keys {
+			inf.inferExpression(key, sc)
+			inf.inferExpression(expr.Values[i], sc)
+		}
+		return hashType
+	case *ast.Identifier:
+		if s, ok := sc.get(expr.Name); ok {
+			return inf.instantiate(s)
+		}
+		return anyType
+	case *ast.FunctionLiteral:
+		return inf.inferFunction(expr, "", sc)
+	case *ast.StructLiteral:
+		return constructorType(expr.Fields, &tcon{name: "struct"})
+	case *ast.PrefixExpression:
+		right := inf.inferExpression(expr.Right, sc)
+		if !inf.requireNumber(right) {
+			inf.errorf(expr.Token.Pos, "operator %s expects number but got %s", expr.Operator, typeString(right))
+			return anyType
+		}
+		return right
+	case *ast.InfixExpression:
+		left := inf.inferExpression(expr.Left, sc)
+		right := inf.inferExpression(expr.Right, sc)
+		switch expr.Operator {
+		case "+", "-", "*", "/", "%":
+			return inf.inferArithmetic(expr.Operator, left, right, expr.Token.Pos)
+		case "<", "<=", ">", ">=":
+			inf.inferComparison(expr.Operator, left, right, expr.Token.Pos)
+		}
+		return boolType
+	case *ast.FunctionCalling:
+		return inf.inferCall(expr, sc)
+	case *ast.IndexExpression:
+		left := inf.inferExpression(expr.Left, sc)
+		inf.inferExpression(expr.Index, sc)
+		switch l := prune(left).(type) {
+		case *tcon:
+			switch l.name {
+			case string(String):
+				return stringType
+			case string(Any), string(Array), string(Hash):
+				return anyType
+			}
+		case *tvar:
+			if !l.numeric {
+				return anyType
+			}
+		}
+		inf.errorf(expr.Token.Pos, "%s is not indexable", typeString(left))
+		return anyType
+	case *ast.MemberExpression:
+		inf.inferExpression(expr.Object, sc)
+		return anyType
+	case *ast.MatchExpression:
+		return inf.inferMatch(expr, sc)
+	case *ast.NamedArgument:
+		return inf.inferExpression(expr.Value, sc)
+	}
+	return anyType
+}
+
+// intかfloatでなければならない
+func (inf *Inferer) requireNumber(t term) bool {
+	switch t := prune(t).(type) {
+	case *tvar:
+		t.numeric = true
+		return true
+	case *tcon:
+		return isNumericCon(t)
+	}
+	return false
+}
+
+// 両辺は同じ型とみなし、どちらかがfloatならfloatになる
+func (inf *Inferer) inferArithmetic(operator string, left term, right term, pos token.Position) term {
+	for _, t := range []term{left, right} {
+		if !inf.requireNumber(t) {
+			inf.errorf(pos, "operator %s expects number but got %s", operator, typeString(t))
+			return anyType
+		}
+	}
+	inf.unify(left, right)
+	for _, t := range []term{left, right} {
+		if c, ok := prune(t).(*tcon); ok && c.name == string(Float) {
+			return floatType
+		}
+	}
+	return prune(left)
+}
+
+// 数のほか、文字列どうしと配列どうしも比べられる
+func (inf *Inferer) inferComparison(operator string, left term, right term, pos token.Position) {
+	for _, t := range []term{left, right} {
+		if c, ok := prune(t).(*tcon); ok {
+			switch c.name {
+			case string(Int), string(Float), string(String), string(Array), string(Any):
+				continue
+			}
+		} else if _, ok := prune(t).(*tvar); ok {
+			continue
+		}
+		inf.errorf(pos, "operator %s expects number, string or array but got %s", operator, typeString(t))
+		return
+	}
+	if !inf.unify(left, right) {
+		inf.errorf(pos, "operator %s cannot compare %s with %s", operator, typeString(left), typeString(right))
+	}
+}
+
+// Empty のような大文字の名前は、すでにあれば構造体や列挙型の値として一致を調べるだけで束縛しない
+func isConstructorName(name string, sc *typeScope) bool {
+	_, ok := sc.get(name)
+	return ok && 'A' <= name[0] && name[0] <= 'Z'
+}
+
+// パターンは一致しないこともあるので、値の型は決めない
+// 腕の型がすべて同じならその型
+func (inf *Inferer) inferMatch(me *ast.MatchExpression, sc *typeScope) term {
+	value := inf.inferExpression(me.Value, sc)
+	var result term
+	for _, arm := range me.Arms {
+		inner := newTypeScope(sc)
+		if bp, ok := arm.Pattern.(*ast.BindingPattern); ok && !isConstructorName(bp.Name.Name, sc) {
+			inner.set(bp.Name.Name, value)
+		} else {
+			for _, name := range arm.Pattern.Names() {
+				inner.set(name, anyType)
+			}
+		}
+		if arm.Guard != nil {
+			inf.inferExpression(arm.Guard, inner)
+		}
+		t := inf.inferExpression(arm.Body, inner)
+		if result == nil {
+			result = t
+		} else if typeString(result) != typeString(t) {
+			result = anyType
+		}
+	}
+	if result == nil {
+		return anyType
+	}
+	return result
+}
+
+func (inf *Inferer) inferCall(fc *ast.FunctionCalling, sc *typeScope) term {
+	callee := inf.inferExpression(fc.Function, sc)
+	var args []term
+	var positions []token.Position
+	named := map[string]term{ }
+	for _, arg := range fc.Arguments {
+		t := inf.inferExpression(arg, sc)
+		if na, ok := arg.(*ast.NamedArgument); ok {
+			named[na.Name.Name] = t
+			continue
+		}
+		args = append(args, t)
+		positions = append(positions, position(arg))
+	}
+
+	// メソッドの型はわからない
+	if _, ok := fc.Function.(*ast.MemberExpression); ok {
+		return anyType
+	}
+	switch fn := prune(callee).(type) {
+	case *tvar:
+		if len(named) != 0 {
+			return anyType
+		}
+		ret := inf.fresh()
+		call := &tfunc{params: args, required: len(args), ret: ret}
+		if occurs(fn, call) {
+			inf.errorf(position(fc.Function), "%s cannot be applied to itself", fc.Function.String())
+			return anyType
+		}
+		if !inf.unify(fn, call) {
+			inf.errorf(position(fc.Function), "%s is %s, not a function", fc.Function.String(), typeString(fn))
+			return anyType
+		}
+		return ret
+	case *tcon:
+		if fn.name != string(Any) && fn.name != string(Function) {
+			inf.errorf(position(fc.Function), "%s is %s, not a function", fc.Function.String(), typeString(fn))
+		}
+		return anyType
+	case *tfunc:
+		inf.checkCallArguments(fc, fn, args, positions, named)
+		return fn.ret
+	}
+	return anyType
+}
+
+func (inf *Inferer) checkCallArguments(fc *ast.FunctionCalling, fn *tfunc, args []term, positions []token.Position, named map[string]term) {
+	name := fc.Function.String()
+	count := len(args) + len(named)
+	max := len(fn.params)
+	if fn.rest {
+		max = -1
+	}
+	if count < fn.required || max >= 0 && count > max {
+		inf.errorf(fc.Token.Pos, "%s expects %s arguments but got %d", name, object.ArityString(fn.required, max), count)
+		return
+	}
+	for i, t := range args {
+		if i >= len(fn.params) {
+			break
+		}
+		if !inf.unify(fn.params[i], t) {
+			inf.errorf(positions[i], "argument %s of %s expects %s but got %s", paramName(fn, i), name, typeString(fn.params[i]), typeString(t))
+		}
+	}
+	for i, n := range fn.names {
+		t, ok := named[n]
+		if ok && !inf.unify(fn.params[i], t) {
+			inf.errorf(fc.Token.Pos, "argument %s of %s expects %s but got %s", n, name, typeString(fn.params[i]), typeString(t))
+		}
+	}
+}
+
+func paramName(fn *tfunc, i int) string {
+	if i < len(fn.names) {
+		return fn.names[i]
+	}
+	return fmt.Sprint(i + 1)
+}
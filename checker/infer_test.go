@@ -0,0 +1,112 @@
+package checker
+
+import (
+	"testing"
+
+	"yokan/ast"
+	"yokan/lexer"
+	"yokan/parser"
+)
+
+func TestInferType(t *testing.T) {
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{"1", "int"},
+		{"1 + 2.5", "float"},
+		{"-inf", "float"},
+		{"\"a\"", "string"},
+		{"1 < 2", "bool"},
+		{"[1, \"a\"]", "array"},
+		{"remainder = (n, d){ n - (n / d) * d }\n remainder", "int -> int -> int"},
+		{"id = x => x\n id", "a -> a"},
+		{"id = x => x\n id(\"a\")", "string"},
+		{"compose = (f, g) => x => f(g(x))\n compose", "(a -> b) -> (c -> a) -> c -> b"},
+		{"twice = f => x => f(f(x))\n twice(x => x + 1)", "int -> int"},
+		{"f = x => x(1) + 1\n f", "(int -> int) -> int"},
+		{"(a, b = 1, ...rest) => a", "a -> int -> ...any -> a"},
+		{"() => null", "() -> null"},
+		{"half = (x: float) => x / 2\n half", "float -> float"},
+		{"Point = struct(x, y)\n Point", "any -> any -> Point"},
+		{"enum Shape { Circle(r), Empty }\n Empty", "Shape"},
+		{"match (1) { 0 => \"zero\", _ => \"other\" }", "string"},
+		{"match (1) { 0 => \"zero\", _ => 1 }", "any"},
+		{"x = 1\n x = \"a\"\n x", "string"},
+		{"len", "any -> any"},
+		{"inc = x => x + 1", "int -> int"},
+		{"add = (a, b) => a + b\n add(1.5, 2)", "int"},
+	}
+	for _, tt := range tests {
+		got, errors := NewInferer().TypeOf(parse(t, tt.input))
+		if len(errors) != 0 {
+			t.Errorf("%s: inferer has errors %q", tt.input, errors)
+		}
+		if got != tt.expected {
+			t.Errorf("%s: type is not %q. got=%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestInferErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{"\"a\" + 1", "1:5: operator + expects number but got string"},
+		{"-\"a\"", "1:1: operator - expects number but got string"},
+		{"x = 1\n x(2)", "2:2: x is int, not a function"},
+		{"f = x => x + 1\n f(\"a\")", "2:4: argument x of f expects int but got string"},
+		{"f = x => x + 1\n f(x: \"a\")", "2:3: argument x of f expects int but got string"},
+		{"f = (a, b) => a\n f(1)", "2:3: f expects 2 arguments but got 1"},
+		{"apply = (f, x) => f(x)\n apply(1, 2)", "2:8: argument f of apply expects a -> b but got int"},
+		{"f = x => x(x)", "1:10: x cannot be applied to itself"},
+		{"f = s => s + 1\n g = () => f(\"a\")", "2:14: argument s of f expects int but got string"},
+		{"1 < \"a\"", "1:3: operator < cannot compare int with string"},
+		{"true < false", "1:6: operator < expects number, string or array but got bool"},
+		{"1[0]", "1:2: int is not indexable"},
+		{"x: int = \"a\"", "1:1: x is declared as int but got string"},
+		// 注釈で書いたintとfloatは、checkと同じように区別する
+		{"f = (n: int) { n + 1 }\n f(2.5)", "2:4: argument n of f expects int but got float"},
+		{"x: float = 1", "1:1: x is declared as float but got int"},
+		{"f = (): float { 1 }", "1:9: f should return float but returns int"},
+		{"f = (n): string => n + 1", "1:10: f should return string but returns int"},
+		{"x = 1\n x += \"a\"", "2:4: operator + expects number but got string"},
+		{"f = (n) {\n requires n + 1\n n\n }", "2:11: requires expects bool but got int"},
+		{"f = (n) {\n ensures result == \"a\" + 1\n n\n }", "2:24: operator + expects number but got string"},
+	}
+	for _, tt := range tests {
+		errors := Infer(parse(t, tt.input))
+		if len(errors) != 1 || errors[0] != tt.expected {
+			t.Errorf("%s: errors is not [%q]. got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+
+// 前の入力で代入した変数の型を覚えている
+func TestInfererKeepsBindings(t *testing.T) {
+	inf := NewInferer()
+	if errors := inf.Program(parse(t, "add = (a, b) => a + b")); len(errors) != 0 {
+		t.Fatalf("inferer has errors %q", errors)
+	}
+	if got, _ := inf.TypeOf(parse(t, "add")); got != "int -> int -> int" {
+		t.Errorf("type of add is not int -> int -> int. got=%q", got)
+	}
+	if errors := inf.Program(parse(t, "add(\"a\", 1)")); len(errors) != 1 {
+		t.Errorf("inferer has no errors for add(\"a\", 1). got=%q", errors)
+	}
+	// :type で代入しても覚えない
+	inf.TypeOf(parse(t, "x = 1"))
+	if got, _ := inf.TypeOf(parse(t, "x")); got != "any" {
+		t.Errorf("type of x is not any. got=%q", got)
+	}
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("%s: parser has errors %q", input, p.Errors())
+	}
+	return program
+}
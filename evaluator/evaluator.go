@@ -2,9 +2,16 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"strings"
 
 	"yokan/ast"
+	"yokan/lexer"
 	"yokan/object"
+	"yokan/parser"
+	"yokan/token"
 )
 
 func Eval(node ast.Node, env *object.Environment) object.Object {
@@ -16,15 +23,38 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return Eval(node.Expression, env)
 	case *ast.Assign:
 		return evalAssign(*node, env)
+	case *ast.DestructuringAssign:
+		return evalDestructuringAssign(node, env)
+	case *ast.IndexAssign:
+		return evalIndexAssign(node, env)
+	case *ast.MemberAssign:
+		return evalMemberAssign(node, env)
+	case *ast.EnumStatement:
+		return evalEnumStatement(node, env)
+	case *ast.CompoundAssign:
+		return evalCompoundAssign(node, env)
 	
 	case *ast.FunctionLiteral:
-		return &object.Function{Parameters: node.Arguments, Body: node.Body, Env: env}
+		return &object.Function{
+			Parameters: node.Arguments,
+			Defaults: node.Defaults,
+			Patterns: node.Patterns,
+			Rest: node.Rest,
+			Requires: node.Requires,
+			Ensures: node.Ensures,
+			Name: node.Name,
+			Body: node.Body,
+			Env: env,
+		}
 	case *ast.FunctionCalling:
+		if me, ok := node.Function.(*ast.MemberExpression); ok {
+			return evalMethodCall(node, me, env)
+		}
 		function := Eval(node.Function, env)
 		if isError(function) { return function }
-		args := evalExpressions(node.Arguments, env)
-		if len(args) == 1 && isError(args[0]) { return args[0] }
-		return applyFunction(function, args)
+		args, err := evalArguments(function, node.Arguments, env)
+		if err != nil { return err }
+		return applyFunction(function, args, newContext(node.Token.Pos, env))
 	
 	case *ast.PrefixExpression:
 		right := Eval(node.Right, env)
@@ -43,9 +73,40 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return val
 
 	case *ast.IntegerLiteral:
+		if node.BigValue != nil {
+			return &object.BigInteger{Value: node.BigValue}
+		}
 		return &object.Integer{Value: node.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
+	case *ast.ArrayLiteral:
+		elements := evalExpressions(node.Value, env)
+		if len(elements) == 1 && isError(elements[0]) { return elements[0] }
+		return &object.Array{Elements: elements}
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env)
+	case *ast.IndexExpression:
+		left := Eval(node.Left, env)
+		if isError(left) { return left }
+		index := Eval(node.Index, env)
+		if isError(index) { return index }
+		return evalIndex(left, index)
+	case *ast.MemberExpression:
+		obj := Eval(node.Object, env)
+		if isError(obj) { return obj }
+		return evalMember(obj, node.Member.Name)
+	case *ast.StructLiteral:
+		var fields []string
+		for _, f := range node.Fields {
+			fields = append(fields, f.Name)
+		}
+		return object.NewStructType(node.Name, fields)
+	case *ast.MatchExpression:
+		return evalMatchExpression(node, env)
+	case *ast.NamedArgument:
+		return &object.OtherError{Msg: fmt.Sprintf("keyword argument %s is only allowed in function calls", node.Name.Name)}
 	}
 	return &object.OtherError{Msg: fmt.Sprintf("%T is not yet implemented", node)}
 }
@@ -68,8 +129,21 @@ func evalAssign(assign ast.Assign, env *object.Environment) object.Object {
 	return &object.ReturnValueOsStatement{ }
 }
 
+func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	hash := object.NewHash()
+	for i, keyNode := range node.Keys {
+		key := Eval(keyNode, env)
+		if isError(key) { return key }
+		value := Eval(node.Values[i], env)
+		if isError(value) { return value }
+		err := hash.Set(key, value)
+		if err != nil { return err }
+	}
+	return hash
+}
+
 func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
-	var result []object.Object
+	result := []object.Object{ }
 	for _, e := range exps {
 		evaled := Eval(e, env)
 		if isError(evaled) { return []object.Object{evaled} }
@@ -78,19 +152,119 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 	return result
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
+// 名前付き引数があれば、呼ぶ関数の引数の位置に並べ替える
+func evalArguments(fn object.Object, exps []ast.Expression, env *object.Environment) ([]object.Object, object.Object) {
+	var args []object.Object
+	var names []string
+	var values []object.Object
+	for _, e := range exps {
+		if na, ok := e.(*ast.NamedArgument); ok {
+			value := Eval(na.Value, env)
+			if isError(value) { return nil, value }
+			names = append(names, na.Name.Name)
+			values = append(values, value)
+			continue
+		}
+		evaled := Eval(e, env)
+		if isError(evaled) { return nil, evaled }
+		args = append(args, evaled)
+	}
+	if args == nil {
+		args = []object.Object{ }
+	}
+	if len(names) == 0 {
+		return args, nil
+	}
 	switch fn := fn.(type) {
 	case *object.Function:
-		if len(fn.Parameters) != len(args) {
-			return &object.OtherError {
-				Msg: fmt.Sprintf("Function need %d params, but got %d params", len(fn.Parameters), len(args)),
+		return bindKeywordArguments(fn, args, names, values)
+	case *object.Buildin:
+		if fn.Signature == nil {
+			return nil, &object.OtherError{Msg: "this buildin function does not accept keyword arguments"}
+		}
+		return fn.Signature.BindKeywords(args, names, values)
+	case *object.StructType:
+		return fn.Signature.BindKeywords(args, names, values)
+	}
+	// 関数でなければapplyFunctionでエラーにする
+	return args, nil
+}
+
+// 渡されなかった引数はnilにしておき、inheritFunctionEnvで初期値を入れる
+func bindKeywordArguments(fn *object.Function, args []object.Object, names []string, values []object.Object) ([]object.Object, object.Object) {
+	bound := append([]object.Object{ }, args...)
+	for i, name := range names {
+		idx := -1
+		for j, param := range fn.Parameters {
+			if param.Name == name {
+				idx = j
 			}
 		}
-		inheritEnv := inheritFunctionEnv(fn, args)
-		a := evalStatements(fn.Body, inheritEnv)
-		return a
+		if idx < 0 {
+			return nil, &object.OtherError{Msg: fmt.Sprintf("Function got an unknown keyword argument %s", name)}
+		}
+		for len(bound) <= idx {
+			bound = append(bound, nil)
+		}
+		if bound[idx] != nil {
+			return nil, &object.OtherError{Msg: fmt.Sprintf("Function got multiple values for argument %s", name)}
+		}
+		bound[idx] = values[i]
+	}
+	for i, param := range fn.Parameters {
+		hasDefault := i < len(fn.Defaults) && fn.Defaults[i] != nil
+		if (i >= len(bound) || bound[i] == nil) && !hasDefault {
+			return nil, &object.OtherError{Msg: fmt.Sprintf("Function missing argument %s", param.Name)}
+		}
+	}
+	return bound, nil
+}
+
+// 組み込み関数に渡す、呼び出し元の状況を作る
+func newContext(pos token.Position, env *object.Environment) *object.Context {
+	ctx := &object.Context{
+		Env: env,
+		Position: pos,
+		Out: env.Output(),
+		In: env.Input(),
+		Eval: Eval,
+		Parse: parseSource,
+		Load: loadSource,
+	}
+	ctx.Apply = func(fn object.Object, args []object.Object) object.Object {
+		return applyFunction(fn, args, ctx)
+	}
+	return ctx
+}
+
+// 組み込みのevalとimportがソースを読むときに使う
+func parseSource(src string) (*ast.Program, []string) {
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	return program, p.Errors()
+}
+
+func loadSource(path string) (*ast.Program, []string, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	program, errors := parseSource(string(src))
+	return program, errors, nil
+}
+
+func applyFunction(fn object.Object, args []object.Object, ctx *object.Context) object.Object {
+	switch fn := fn.(type) {
+	case *object.Function:
+		return callFunction(fn, args, nil)
 	case *object.Buildin:
-		return fn.Fn(args...)
+		return fn.Call(ctx, args)
+	case *object.StructType:
+		if len(args) < len(fn.Fields) {
+			return &object.OtherError{Msg: fmt.Sprintf("%s missing field %s", fn.Name, fn.Fields[len(args)])}
+		}
+		if err := fn.Signature.Check(args); err != nil { return err }
+		return fn.New(args)
 	default:
 		return &object.OtherError {
 			Msg: fmt.Sprintf("%s(%s) is not a function", fn.Type(), fn.String()),
@@ -98,12 +272,91 @@ func applyFunction(fn object.Object, args []object.Object) object.Object {
 	}
 }
 
-func inheritFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
+// selfがnilでなければ、メソッドとして呼ばれたのでselfに束縛する
+func callFunction(fn *object.Function, args []object.Object, self object.Object) object.Object {
+	min, max := fn.Arity()
+	if len(args) < min || max >= 0 && len(args) > max {
+		return &object.OtherError {
+			Msg: fmt.Sprintf("Function need %s params, but got %d params", object.ArityString(min, max), len(args)),
+		}
+	}
+	inheritEnv, err := inheritFunctionEnv(fn, args, self)
+	if err != nil { return err }
+	if !fn.Env.ContractsEnabled() {
+		return evalStatements(fn.Body, inheritEnv)
+	}
+	for _, cond := range fn.Requires {
+		if err := checkContract(fn, "requires", cond, inheritEnv, args); err != nil { return err }
+	}
+	a := evalStatements(fn.Body, inheritEnv)
+	if isError(a) || len(fn.Ensures) == 0 { return a }
+	// ensuresの中では戻り値をresultで参照する
+	resultEnv := object.NewInferitEnvironment(inheritEnv)
+	resultEnv.Set("result", a)
+	for _, cond := range fn.Ensures {
+		if err := checkContract(fn, "ensures", cond, resultEnv, []object.Object{a}); err != nil { return err }
+	}
+	return a
+}
+
+// 条件がfalseなら、関数の名前と破った条件をエラーにする
+// requiresなら渡された引数、ensuresなら戻り値を添える
+func checkContract(fn *object.Function, clause string, cond ast.Expression, env *object.Environment, values []object.Object) object.Object {
+	result := Eval(cond, env)
+	if isError(result) { return result }
+	b, ok := result.(*object.Boolean)
+	if !ok {
+		return &object.TypeMisMatchError{Name: clause, Expected: object.BOOLEAN_OBJ, Got: result}
+	}
+	if b.Value { return nil }
+	name := fn.Name
+	if name == "" {
+		name = "function"
+	}
+	var strs []string
+	for _, v := range values {
+		if v != nil {
+			strs = append(strs, v.String())
+		}
+	}
+	given := "called with " + strings.Join(strs, ", ")
+	if clause == "ensures" {
+		given = "returned " + strs[0]
+	} else if len(strs) == 0 {
+		given = "called with no arguments"
+	}
+	return &object.OtherError{Msg: fmt.Sprintf("contract violation: %s %s %s (%s)", name, clause, cond.String(), given)}
+}
+
+// 渡されなかった引数の初期値は、呼ぶたびにそれより前の引数が見える環境で評価する
+func inheritFunctionEnv(fn *object.Function, args []object.Object, self object.Object) (*object.Environment, object.Object) {
 	env := object.NewInferitEnvironment(fn.Env)
+	if self != nil {
+		env.Set("self", self)
+	}
 	for paramIdx, param := range fn.Parameters {
-		env.Set(param.Name, args[paramIdx])
+		var val object.Object
+		if paramIdx < len(args) && args[paramIdx] != nil {
+			val = args[paramIdx]
+		} else {
+			val = Eval(fn.Defaults[paramIdx], env)
+			if isError(val) { return nil, val }
+		}
+		if paramIdx < len(fn.Patterns) && fn.Patterns[paramIdx] != nil {
+			err := destructure(fn.Patterns[paramIdx], val, env)
+			if err != nil { return nil, err }
+			continue
+		}
+		env.Set(param.Name, val)
+	}
+	if fn.Rest != nil {
+		rest := []object.Object{ }
+		if len(args) > len(fn.Parameters) {
+			rest = append(rest, args[len(fn.Parameters):]...)
+		}
+		env.Set(fn.Rest.Name, &object.Array{Elements: rest})
 	}
-	return env
+	return env, nil
 }
 
 func evalPrefixExpression(operator string, right object.Object) object.Object {
@@ -118,16 +371,21 @@ func evalPrefixExpression(operator string, right object.Object) object.Object {
 }
 
 func evalPlusPrefixOperatorExpression(right object.Object) object.Object {
-	err, ok := checkTypeIsInteger("PlusPrefixOperator", right)
+	err, ok := checkTypeIsNumber("PlusPrefixOperator", right)
 	if !ok { return err }
 	return right
 }
 
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
-	err, ok := checkTypeIsInteger("MinuPrefixOperator", right)
+	err, ok := checkTypeIsNumber("MinuPrefixOperator", right)
 	if !ok { return err }
-	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
+	if right.Type() == object.FLOAT_OBJ {
+		return &object.Float{Value: -right.(*object.Float).Value}
+	}
+	if right, ok := right.(*object.Integer); ok && right.Value != math.MinInt64 {
+		return &object.Integer{Value: -right.Value}
+	}
+	return object.NewInteger(new(big.Int).Neg(object.ToBig(right)))
 }
 
 func evalInfixExpression(left object.Object, operator string, right object.Object) object.Object {
@@ -140,6 +398,8 @@ func evalInfixExpression(left object.Object, operator string, right object.Objec
 		return evalStarInfixOperatorExpression(left, right)
 	case "/":
 		return evalSlashInfixOperatorExpression(left, right)
+	case "%":
+		return evalPercentInfixOperatorExpression(left, right)
 	case "==":
 
 		return evalEqInfixOperatorExpression(left, right)
@@ -160,109 +420,168 @@ func evalInfixExpression(left object.Object, operator string, right object.Objec
 
 func evalPlusInfixOperatorExpression(left object.Object, right object.Object) object.Object {
 	{
-		err, ok := checkTypeIsInteger("PlusInfixOperator", left)
+		err, ok := checkTypeIsNumber("PlusInfixOperator", left)
 		if !ok { return err }
 	}
 	{
-		err, ok := checkTypeIsInteger("PlusInfixOperator", right)
+		err, ok := checkTypeIsNumber("PlusInfixOperator", right)
 		if !ok { return err }
 	}
-	l := left.(*object.Integer).Value
-	r := right.(*object.Integer).Value
-	return &object.Integer{Value: l+r}
+	if isFloatOperation(left, right) {
+		return &object.Float{Value: object.ToFloat(left)+object.ToFloat(right)}
+	}
+	if !isBigOperation(left, right) {
+		l := left.(*object.Integer).Value
+		r := right.(*object.Integer).Value
+		sum := l+r
+		// 符号が同じ数を足して符号が変わったらあふれている
+		if (l^sum)&(r^sum) >= 0 {
+			return &object.Integer{Value: sum}
+		}
+	}
+	return object.NewInteger(new(big.Int).Add(object.ToBig(left), object.ToBig(right)))
 }
 
 func evalMinusInfixOperatorExpression(left object.Object, right object.Object) object.Object {
 	{
-		err, ok := checkTypeIsInteger("MinusInfixOperator", left)
+		err, ok := checkTypeIsNumber("MinusInfixOperator", left)
 		if !ok { return err }
 	}
 	{
-		err, ok := checkTypeIsInteger("MinusInfixOperator", right)
+		err, ok := checkTypeIsNumber("MinusInfixOperator", right)
 		if !ok { return err }
 	}
-	l := left.(*object.Integer).Value
-	r := right.(*object.Integer).Value
-	return &object.Integer{Value: l-r}
+	if isFloatOperation(left, right) {
+		return &object.Float{Value: object.ToFloat(left)-object.ToFloat(right)}
+	}
+	if !isBigOperation(left, right) {
+		l := left.(*object.Integer).Value
+		r := right.(*object.Integer).Value
+		diff := l-r
+		if (l^r)&(l^diff) >= 0 {
+			return &object.Integer{Value: diff}
+		}
+	}
+	return object.NewInteger(new(big.Int).Sub(object.ToBig(left), object.ToBig(right)))
 }
 
 func evalStarInfixOperatorExpression(left object.Object, right object.Object) object.Object {
 	{
-		err, ok := checkTypeIsInteger("StarInfixOperator", left)
+		err, ok := checkTypeIsNumber("StarInfixOperator", left)
 		if !ok { return err }
 	}
 	{
-		err, ok := checkTypeIsInteger("StarInfixOperator", right)
+		err, ok := checkTypeIsNumber("StarInfixOperator", right)
 		if !ok { return err }
 	}
-	l := left.(*object.Integer).Value
-	r := right.(*object.Integer).Value
-	return &object.Integer{Value: l*r}
+	if isFloatOperation(left, right) {
+		return &object.Float{Value: object.ToFloat(left)*object.ToFloat(right)}
+	}
+	if !isBigOperation(left, right) {
+		l := left.(*object.Integer).Value
+		r := right.(*object.Integer).Value
+		if l == 0 || r == 0 {
+			return &object.Integer{Value: 0}
+		}
+		product := l*r
+		if product/r == l && !(l == -1 && r == math.MinInt64) && !(r == -1 && l == math.MinInt64) {
+			return &object.Integer{Value: product}
+		}
+	}
+	return object.NewInteger(new(big.Int).Mul(object.ToBig(left), object.ToBig(right)))
 }
 
 func evalSlashInfixOperatorExpression(left object.Object, right object.Object) object.Object {
 	{
-		err, ok := checkTypeIsInteger("SlashInfixOperator", left)
+		err, ok := checkTypeIsNumber("SlashInfixOperator", left)
 		if !ok { return err }
 	}
 	{
-		err, ok := checkTypeIsInteger("SlashInfixOperator", right)
+		err, ok := checkTypeIsNumber("SlashInfixOperator", right)
 		if !ok { return err }
 	}
-	l := left.(*object.Integer).Value
-	r := right.(*object.Integer).Value
-	if r==0 {
+	if isFloatOperation(left, right) {
+		r := object.ToFloat(right)
+		if r==0 {
+			return &object.OtherError{Msg: "Zero division Error"}
+		}
+		return &object.Float{Value: object.ToFloat(left)/r}
+	}
+	if !isBigOperation(left, right) {
+		l := left.(*object.Integer).Value
+		r := right.(*object.Integer).Value
+		if r==0 {
+			return &object.OtherError{Msg: "Zero division Error"}
+		}
+		if !(l == math.MinInt64 && r == -1) {
+			return &object.Integer{Value: l/r}
+		}
+	}
+	r := object.ToBig(right)
+	if r.Sign() == 0 {
 		return &object.OtherError{Msg: "Zero division Error"}
 	}
-	return &object.Integer{Value: l/r}
+	// Divはユークリッド除算なので、int64の/と同じく0に向かって切り捨てるQuoを使う
+	return object.NewInteger(new(big.Int).Quo(object.ToBig(left), r))
 }
 
-func checkTypeIsInteger(name string, val object.Object) (object.Object, bool) {
-	if val.Type() != object.INTEGER_OBJ {
-		return &object.TypeMisMatchError{Name: name, Expected: object.INTEGER_OBJ, Got: val}, false
+// 余りの符号は/と同じく左側に合わせる
+func evalPercentInfixOperatorExpression(left object.Object, right object.Object) object.Object {
+	{
+		err, ok := checkTypeIsNumber("PercentInfixOperator", left)
+		if !ok { return err }
+	}
+	{
+		err, ok := checkTypeIsNumber("PercentInfixOperator", right)
+		if !ok { return err }
+	}
+	if isFloatOperation(left, right) {
+		r := object.ToFloat(right)
+		if r==0 {
+			return &object.OtherError{Msg: "Zero division Error"}
+		}
+		return &object.Float{Value: math.Mod(object.ToFloat(left), r)}
+	}
+	if !isBigOperation(left, right) {
+		l := left.(*object.Integer).Value
+		r := right.(*object.Integer).Value
+		if r==0 {
+			return &object.OtherError{Msg: "Zero division Error"}
+		}
+		if r == -1 {
+			return &object.Integer{Value: 0}
+		}
+		return &object.Integer{Value: l%r}
+	}
+	r := object.ToBig(right)
+	if r.Sign() == 0 {
+		return &object.OtherError{Msg: "Zero division Error"}
+	}
+	return object.NewInteger(new(big.Int).Rem(object.ToBig(left), r))
+}
+
+func checkTypeIsNumber(name string, val object.Object) (object.Object, bool) {
+	if val.Type() != object.INTEGER_OBJ && val.Type() != object.FLOAT_OBJ {
+		return &object.TypeMisMatchError{Name: name, Expected: object.INTEGER_OBJ+", "+object.FLOAT_OBJ, Got: val}, false
 	}
 	return nil, true
 }
 
-var comparableInEqInfixOperatorTypes = []object.ObjectType {
-	object.INTEGER_OBJ,
-	object.STRING_OBJ,
-	object.BOOLEAN_OBJ,
-	object.NULL_OBJ,
+// どちらかが浮動小数点数なら、もう片方も浮動小数点数にして計算する
+func isFloatOperation(left object.Object, right object.Object) bool {
+	return left.Type() == object.FLOAT_OBJ || right.Type() == object.FLOAT_OBJ
+}
+
+// int64に収まらない整数が混ざっていたら、math/bigで計算する
+func isBigOperation(left object.Object, right object.Object) bool {
+	_, leftBig := left.(*object.BigInteger)
+	_, rightBig := right.(*object.BigInteger)
+	return leftBig || rightBig
 }
-var comparableInEqInfixOperatorTypesName = 
-	object.INTEGER_OBJ+", "+object.STRING_OBJ+", "+object.BOOLEAN_OBJ+", "+object.NULL_OBJ
 
+// 配列やハッシュは中身を比べ、関数は同じものかどうかで比べる
 func evalEqInfixOperatorExpression(left object.Object, right object.Object) object.Object {
-	if !contains(left.Type(), comparableInEqInfixOperatorTypes) {
-		return &object.TypeMisMatchError{
-			Name: "EqInfixOperator",
-			Expected: comparableInEqInfixOperatorTypesName,
-			Got: left,
-		}
-	}
-	if !contains(right.Type(), comparableInEqInfixOperatorTypes) {
-		return &object.TypeMisMatchError{
-			Name: "EqInfixOperator",
-			Expected: comparableInEqInfixOperatorTypesName,
-			Got: right,
-		}
-	}
-	if (
-		left.Type() == object.INTEGER_OBJ &&
-		right.Type() == object.INTEGER_OBJ &&
-		left.(*object.Integer).Value == right.(*object.Integer).Value ||
-		left.Type() == object.STRING_OBJ &&
-		right.Type() == object.STRING_OBJ &&
-		left.(*object.String).Value == right.(*object.String).Value ||
-		left.Type() == object.BOOLEAN_OBJ &&
-		right.Type() == object.BOOLEAN_OBJ &&
-		left.(*object.Boolean).Value == right.(*object.Boolean).Value ||
-		left.Type() == object.NULL_OBJ &&
-		right.Type() == object.NULL_OBJ ) {
-		return &object.Boolean{Value: true}
-	}
-	return &object.Boolean{Value: false}
+	return &object.Boolean{Value: object.Equals(left, right)}
 }
 
 func evalNotEqInfixOperatorExpression(left object.Object, right object.Object) object.Object {
@@ -270,39 +589,74 @@ func evalNotEqInfixOperatorExpression(left object.Object, right object.Object) o
 }
 
 func evalLTInfixOperatorExpression(left object.Object, right object.Object) object.Object {
-	{
-		err, ok := checkTypeIsInteger("LTInfixOperator", left)
-		if !ok { return err }
-	}
-	{
-		err, ok := checkTypeIsInteger("LTInfixOperator", right)
-		if !ok { return err }
-	}
-	l := left.(*object.Integer).Value
-	r := right.(*object.Integer).Value
-	return &object.Boolean{Value: l<r} 
+	return evalOrderingInfixOperatorExpression("LTInfixOperator", left, right, func(c int) bool { return c < 0 })
 }
 
 func evalLTEQInfixOperatorExpression(left object.Object, right object.Object) object.Object {
+	return evalOrderingInfixOperatorExpression("LTEQInfixOperator", left, right, func(c int) bool { return c <= 0 })
+}
+
+func evalGTInfixOperatorExpression(left object.Object, right object.Object) object.Object {
+	return evalOrderingInfixOperatorExpression("GTInfixOperator", left, right, func(c int) bool { return c > 0 })
+}
+
+func evalGTEQInfixOperatorExpression(left object.Object, right object.Object) object.Object {
+	return evalOrderingInfixOperatorExpression("GTEQInfixOperator", left, right, func(c int) bool { return c >= 0 })
+}
+
+// leftとrightを比べて、左が小さければ負、等しければ0、大きければ正をholdsに渡す
+// nanはどの数とも大小がないので、どの比較もfalseになる
+func evalOrderingInfixOperatorExpression(name string, left object.Object, right object.Object, holds func(c int) bool) object.Object {
+	if isOrderedTogether(left, right) {
+		return &object.Boolean{Value: holds(object.Compare(left, right))}
+	}
 	{
-		err, ok := checkTypeIsInteger("LTInfixOperator", left)
+		err, ok := checkTypeIsNumber(name, left)
 		if !ok { return err }
 	}
 	{
-		err, ok := checkTypeIsInteger("LTInfixOperator", right)
+		err, ok := checkTypeIsNumber(name, right)
 		if !ok { return err }
 	}
+	if isFloatOperation(left, right) {
+		l := object.ToFloat(left)
+		r := object.ToFloat(right)
+		if math.IsNaN(l) || math.IsNaN(r) {
+			return &object.Boolean{Value: false}
+		}
+		return &object.Boolean{Value: holds(compareFloats(l, r))}
+	}
+	if isBigOperation(left, right) {
+		return &object.Boolean{Value: holds(object.ToBig(left).Cmp(object.ToBig(right)))}
+	}
 	l := left.(*object.Integer).Value
 	r := right.(*object.Integer).Value
-	return &object.Boolean{Value: l<=r} 
+	return &object.Boolean{Value: holds(compareInts(l, r))}
 }
 
-func evalGTInfixOperatorExpression(left object.Object, right object.Object) object.Object {
-	return not(evalLTEQInfixOperatorExpression(left, right))
+func compareInts(l int64, r int64) int {
+	switch {
+	case l < r:
+		return -1
+	case l > r:
+		return 1
+	}
+	return 0
 }
 
-func evalGTEQInfixOperatorExpression(left object.Object, right object.Object) object.Object {
-	return not(evalLTInfixOperatorExpression(left, right))
+func compareFloats(l float64, r float64) int {
+	switch {
+	case l < r:
+		return -1
+	case l > r:
+		return 1
+	}
+	return 0
+}
+
+// 文字列どうしと配列どうしは、数と同じように大小を比べられる(配列は辞書順)
+func isOrderedTogether(left object.Object, right object.Object) bool {
+	return left.Type() == right.Type() && (left.Type() == object.STRING_OBJ || left.Type() == object.ARRAY_OBJ)
 }
 
 func not(obj object.Object) object.Object {
@@ -313,14 +667,6 @@ func not(obj object.Object) object.Object {
 	}
 }
 
-func contains(target object.ObjectType, types []object.ObjectType) bool {
-	ret := false
-	for _, t := range types {
-		ret = ret || target == t
-	}
-	return ret
-}
-
 func isError(obj object.Object) bool {
 	return obj.Type() == object.ERROR_OBJ
 }
\ No newline at end of file
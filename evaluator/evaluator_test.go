@@ -1,7 +1,14 @@
 package evaluator
 
 import (
+	"bufio"
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"yokan/ast"
 	"yokan/lexer"
 	"yokan/parser"
 	"yokan/object"
@@ -14,12 +21,21 @@ func TestEvalIntegerExpression(t *testing.T) {
 	} {
 		{"5", 5},
 		{"10", 10},
+		{"0x10", 16},
+		{"0o10", 8},
+		{"0b10", 2},
+		{"1_000_000", 1000000},
+		// 0から始まるだけの数は、int("010")と同じく10進数
+		{"010", 10},
+		{"09", 9},
+		{"0_10", 10},
 	}
 
 	for _, tt := range tests {
 		evaled := testEval(tt.input)
 		testIntegerObject(t, evaled, tt.expected)
 	}
+	testBooleanObject(t, testEval(`int("010") == 010`), true)
 }
 
 func TestEvalStringExpression(t *testing.T) {
@@ -76,6 +92,121 @@ func TestEvalInfixIntegerExpressions(t *testing.T) {
 	}
 }
 
+func TestEvalFloatExpressions(t *testing.T) {
+	tests := []struct {
+		input string
+		expected float64
+	} {
+		{"3.14", 3.14},
+		{"1e-9", 1e-9},
+		{"-2.5", -2.5},
+		{"+2.5", 2.5},
+		{"1.5+2", 3.5},
+		{"2-0.5", 1.5},
+		{"1.5*2", 3.0},
+		{"7/2.0", 3.5},
+		{"7.0/2", 3.5},
+		{"0.1+0.2", 0.30000000000000004},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		testFloatObject(t, evaled, tt.expected)
+	}
+	testIntegerObject(t, testEval("7/2"), 3)
+
+	strings := []struct {
+		input string
+		expected string
+	} {
+		{"3.14", "3.14"},
+		{"1.0", "1.0"},
+		{"100.0", "100.0"},
+		{"1e-9", "1e-09"},
+		{"1e21", "1e+21"},
+		{"0.1+0.2", "0.30000000000000004"},
+		{"2.5e3", "2500.0"},
+		{"1e308 * 10", "inf"},
+		{"-1e308 * 10", "-inf"},
+		{"inf", "inf"},
+		{"-inf", "-inf"},
+	}
+	for _, tt := range strings {
+		evaled := testEval(tt.input)
+		if evaled.String() != tt.expected {
+			t.Errorf("%s: evaled.String() is not %q. got=%q", tt.input, tt.expected, evaled.String())
+		}
+		// 書き出した文字列を読み直すと同じ値になる
+		again := testEval(evaled.String())
+		testFloatObject(t, again, evaled.(*object.Float).Value)
+	}
+	// nanは自分とも等しくないので、読み直してもnanかどうかだけを調べる
+	for _, input := range []string{"inf - inf", "nan"} {
+		evaled := testEval(input)
+		if evaled.String() != "nan" {
+			t.Errorf("%s: evaled.String() is not \"nan\". got=%q", input, evaled.String())
+		}
+		again, ok := testEval(evaled.String()).(*object.Float)
+		if !ok || !math.IsNaN(again.Value) {
+			t.Errorf("%s: nan is not read back as nan", input)
+		}
+	}
+
+	evaled := testEval("1.0 / 0")
+	_, ok := evaled.(*object.OtherError)
+	if !ok {
+		t.Errorf("evaled is not *object.OtherError. got=%T", evaled)
+	}
+}
+
+func TestEvalBigIntegerExpressions(t *testing.T) {
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{"9223372036854775807 + 1", "9223372036854775808"},
+		{"-9223372036854775807 - 2", "-9223372036854775809"},
+		{"4294967296 * 4294967296", "18446744073709551616"},
+		{"-(-9223372036854775807 - 1)", "9223372036854775808"},
+		{"(-9223372036854775807 - 1) / -1", "9223372036854775808"},
+		{"(-9223372036854775807 - 1) * -1", "9223372036854775808"},
+		{"123456789012345678901234567890", "123456789012345678901234567890"},
+		{"123456789012345678901234567890 * 0", "0"},
+		{"-7 / 2", "-3"},
+		{"-70000000000000000000 / 20000000000000000000", "-3"},
+		{"f=(n){ if(n==0, (){1}, (){n*f(n-1)})() }\n f(25)", "15511210043330985984000000"},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		if evaled.Type() != object.INTEGER_OBJ {
+			t.Errorf("%s: evaled.Type() is not INTEGER. got=%s(%s)", tt.input, evaled.Type(), evaled.String())
+			continue
+		}
+		if evaled.String() != tt.expected {
+			t.Errorf("%s: evaled.String() is not %s. got=%s", tt.input, tt.expected, evaled.String())
+		}
+	}
+
+	// int64に収まるようになったら、Integerに戻る
+	testIntegerObject(t, testEval("9223372036854775808 - 1"), 9223372036854775807)
+	testIntegerObject(t, testEval("100000000000000000000 / 10000000000000000000"), 10)
+
+	comparing := []struct {
+		input string
+		expected bool
+	} {
+		{"9223372036854775808 == 9223372036854775807 + 1", true},
+		{"9223372036854775808 != 1", true},
+		{"9223372036854775808 > 9223372036854775807", true},
+		{"-9223372036854775809 < 1", true},
+		{"99999999999999999999 <= 99999999999999999999", true},
+		{"1e19 < 99999999999999999999", true},
+	}
+	for _, tt := range comparing {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+	testFloatObject(t, testEval("18446744073709551616 * 0.5"), 9223372036854775808)
+}
+
 func TestFunction(t *testing.T) {
 	evaled := testEval("f0=(){}\n f0()")
 	_, ok := evaled.(*object.Null)
@@ -105,6 +236,559 @@ func TestFunction(t *testing.T) {
 	}
 }
 
+func TestFunctionDefaultsAndRest(t *testing.T) {
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{"f = (a, b = 10){ [a, b] }\n f(1)", "[1, 10]"},
+		{"f = (a, b = 10){ [a, b] }\n f(1, 2)", "[1, 2]"},
+		{"f = (a, b = a * 2){ b }\n f(4)", "8"},
+		{"n = 0\n f = (a = n){ a }\n n = 5\n f()", "5"},
+		{"f = (first, ...rest){ [first, rest] }\n f(1, 2, 3)", "[1, [2, 3]]"},
+		{"f = (first, ...rest){ rest }\n f(1)", "[]"},
+		{"f = (...xs){ len(xs) }\n f()", "0"},
+		{"f = (a = 1, ...xs){ [a, xs] }\n f()", "[1, []]"},
+		{"apply((a, ...xs){ xs }, [1, 2, 3])", "[2, 3]"},
+		{"(a, b = 2, ...c){ a }", "(a, b = 2, ...c) {\n\ta\n}"},
+		{"f = (a, b = 10){ a }\n f()", "Function need 1 or 2 params, but got 0 params"},
+		{"f = (a, b = 1, c = 2){ a }\n f(1, 2, 3, 4)", "Function need 1 to 3 params, but got 4 params"},
+		{"f = (a, ...b){ a }\n f()", "Function need at least 1 params, but got 0 params"},
+		{"f = (a, b){ a }\n f(1)", "Function need 2 params, but got 1 params"},
+		{"f = (a = 1/0){ a }\n f()", "Zero division Error"},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		if evaled.String() != tt.expected {
+			t.Errorf("%s: evaled.String() is not %s. got=%s", tt.input, tt.expected, evaled.String())
+		}
+	}
+}
+
+func TestArrowFunction(t *testing.T) {
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{"inc = x => x + 1\n inc(1)", "2"},
+		{"add = (a, b) => a + b\n add(1, 2)", "3"},
+		{"(() => 42)()", "42"},
+		{"map([1, 2, 3], x => x * x)", "[1, 4, 9]"},
+		{"filter([1, 2, 3, 4], x => x > 2)", "[3, 4]"},
+		{"reduce([1, 2, 3], (acc, x) => acc + x)", "6"},
+		{"adder = x => y => x + y\n adder(1)(2)", "3"},
+		{"f = (a, b = 10) => a + b\n f(1)", "11"},
+		{"f = (\n  a,\n  b\n){ a - b }\n f(5, 3)", "2"},
+		{"f = (a, b, c, d){ a + b + c + d }\n f(1, 2, 3, 4)", "10"},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		if evaled.String() != tt.expected {
+			t.Errorf("%s: evaled.String() is not %s. got=%s", tt.input, tt.expected, evaled.String())
+		}
+	}
+}
+
+func TestPipe(t *testing.T) {
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{"[3, 1, 2] |> sort", "[1, 2, 3]"},
+		{`"a,b,c" |> split(",")`, `["a", "b", "c"]`},
+		{"range(1, 7) |> filter(x => x / 2 * 2 == x) |> map(x => x * 10) |> reverse", "[60, 40, 20]"},
+		{`range(3) |> map(str) |> join("-")`, `"0-1-2"`},
+		{"double = x => x * 2\n 5 |> double", "10"},
+		{"1 |> (x => x + 1)", "2"},
+		{"[1, 2, 3]\n  |> map(x => x + 1)\n  |> reduce((a, b) => a + b)", "9"},
+		{"1 |> 2", "INTEGER(2) is not a function"},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		if evaled.String() != tt.expected {
+			t.Errorf("%s: evaled.String() is not %s. got=%s", tt.input, tt.expected, evaled.String())
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{"match (1) { 0 => \"zero\", 1 => \"one\" }", `"one"`},
+		{"match (-2) { -2 => \"minus two\"\n _ => \"other\" }", `"minus two"`},
+		{"match (2.0) { 2 => \"two\" }", `"two"`},
+		{"match (\"b\") { \"a\" => 1\n \"b\" => 2 }", "2"},
+		{"match (null) { false => 1\n null => 2 }", "2"},
+		{"match (5) { n => n * 2 }", "10"},
+		{"match (5) { _ => 0 }", "0"},
+		{"match ([1, 2]) { [x] => x\n [x, y] => x + y }", "3"},
+		{"match ([1, 2, 3]) { [head, ...tail] => [head, tail] }", "[1, [2, 3]]"},
+		{"match ([1]) { [head, ...tail] => tail }", "[]"},
+		{"match ([]) { [head, ...tail] => 1\n [] => 2 }", "2"},
+		{"match ([1, [2, 3]]) { [a, [b, c]] => a + b + c }", "6"},
+		{"match ([1, 2, 3]) { [1, ..._] => \"starts with 1\" }", `"starts with 1"`},
+		{"match ({\"name\": \"yokan\", \"age\": 3}) { {\"name\": n} => n }", `"yokan"`},
+		{"match ({\"a\": 1}) { {\"b\": x} => x\n {\"a\": 2} => 2\n {\"a\": x} => x * 10 }", "10"},
+		{"match ([1, 2]) { {\"a\": x} => x\n _ => \"not hash\" }", `"not hash"`},
+		{"match (15) { n if n > 10 => \"big\"\n n => \"small\" }", `"big"`},
+		{"match (5) { n if n > 10 => \"big\"\n n => \"small\" }", `"small"`},
+		{"limit = 3\n match (5) { n if n > limit => n, _ => 0 }", "5"},
+		{"match (5) { n if (n > 3) => \"big\", _ => \"x\" }", `"big"`},
+		{"match (2) { n if (n > 3) => \"big\", _ => \"x\" }", `"x"`},
+		{"big = n => n > 3\n match (5) { n if big(n) => \"big\", _ => \"x\" }", `"big"`},
+		{"match (5) { n if ((x) => x > 3)(n) => \"big\", _ => \"x\" }", `"big"`},
+		{"x = 1\n match (2) { x => x }\n x", "1"},
+		{"f = match (1) { n => () => n }\n f()", "1"},
+		{"match (1) { 2 => 2 }", "no pattern matched 1 in match at 1:1"},
+		{"match (1) { n if n => 1 }", "match guard Expected BOOLEAN but got 'INTEGER'"},
+		{"match (1/0) { _ => 1 }", "Zero division Error"},
+		{`fizzbuzz = n => match ([n - n / 3 * 3, n - n / 5 * 5]) {
+			[0, 0] => "FizzBuzz"
+			[0, _] => "Fizz"
+			[_, 0] => "Buzz"
+			_ => str(n)
+		}
+		range(1, 16) |> map(fizzbuzz) |> join(" ")`, `"1 2 Fizz 4 Buzz Fizz 7 8 Fizz Buzz 11 Fizz 13 14 FizzBuzz"`},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		if evaled.String() != tt.expected {
+			t.Errorf("%s: evaled.String() is not %s. got=%s", tt.input, tt.expected, evaled.String())
+		}
+	}
+}
+
+func TestDestructuring(t *testing.T) {
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{"[a, b] = [1, 2]\n a + b", "3"},
+		{"[head, ...tail] = [1, 2, 3]\n [head, tail]", "[1, [2, 3]]"},
+		{"[a, [b, c]] = [1, [2, 3]]\n [c, b, a]", "[3, 2, 1]"},
+		{"[_, second] = [1, 2]\n second", "2"},
+		{"{\"name\": n} = {\"name\": \"yokan\", \"age\": 3}\n n", `"yokan"`},
+		{"{\"p\": [x, y]} = {\"p\": [1, 2]}\n x * y", "2"},
+		{"a = 1\n b = 2\n [a, b] = [b, a]\n [a, b]", "[2, 1]"},
+		{"[1, x] = [1, 2]\n x", "2"},
+		{"[a, b] = [1, 2, 3]", "cannot destructure [1, 2, 3] with pattern [a, b]"},
+		{"[a, b] = 1", "cannot destructure 1 with pattern [a, b]"},
+		{"{\"name\": n} = {\"age\": 3}", `cannot destructure {"age": 3} with pattern {"name": n}`},
+		{"a = 0\n [a, 2] = [1, 3]\n a", "cannot destructure [1, 3] with pattern [a, 2]"},
+		{"a = 0\n try((){ [a, 2] = [1, 3] })\n a", "0"},
+		{"f = ([x, y]){ x + y }\n f([1, 2])", "3"},
+		{"f = ([x, ...xs], n){ [x, xs, n] }\n f([1, 2], 3)", "[1, [2], 3]"},
+		{"f = ({\"x\": x}) => x\n f({\"x\": 5})", "5"},
+		{"f = ([a, b] = [1, 2]) => a + b\n f()", "3"},
+		{"map([[1, 2], [3, 4]], ([a, b]) => a * b)", "[2, 12]"},
+		{"f = ([x, y]){ x + y }\n f([1])", "cannot destructure [1] with pattern [x, y]"},
+		{"([a, b]){ a }", "([a, b]) {\n\ta\n}"},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		if evaled.String() != tt.expected {
+			t.Errorf("%s: evaled.String() is not %s. got=%s", tt.input, tt.expected, evaled.String())
+		}
+	}
+}
+
+func TestIndex(t *testing.T) {
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{"[1, 2, 3][0]", "1"},
+		{"[1, 2, 3][2]", "3"},
+		{"[1, 2, 3][-1]", "3"},
+		{"[[1, 2], [3, 4]][1][0]", "3"},
+		{"a = [1, 2, 3]\n i = 1\n a[i + 1]", "3"},
+		{"\"ようかん\"[1]", `"う"`},
+		{"{\"a\": 1, 2: \"b\"}[\"a\"]", "1"},
+		{"{\"a\": 1, 2: \"b\"}[2.0]", `"b"`},
+		{"f = () => [1, 2]\n f()[1]", "2"},
+		{"[1, 2, 3][3]", "index 3 out of range for array of length 3"},
+		{"[1, 2, 3][-4]", "index -4 out of range for array of length 3"},
+		{"[1][99999999999999999999]", "index 99999999999999999999 out of range for array of length 1"},
+		{"\"abc\"[5]", "index 5 out of range for string of length 3"},
+		{"[1, 2][\"a\"]", "array index Expected INTEGER but got 'STRING'"},
+		{"{\"a\": 1}[\"b\"]", `key "b" not found in hash`},
+		{"1[0]", "INTEGER(1) is not indexable"},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		if evaled.String() != tt.expected {
+			t.Errorf("%s: evaled.String() is not %s. got=%s", tt.input, tt.expected, evaled.String())
+		}
+	}
+}
+
+func TestIndexAndCompoundAssign(t *testing.T) {
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{"a = [1, 2, 3]\n a[0] = 10\n a", "[10, 2, 3]"},
+		{"a = [1, 2, 3]\n a[-1] = 30\n a", "[1, 2, 30]"},
+		{"m = [[1, 2], [3, 4]]\n m[1][0] = 0\n m", "[[1, 2], [0, 4]]"},
+		{"h = {\"a\": 1}\n h[\"a\"] = 2\n h[\"b\"] = 3\n h", `{"a": 2, "b": 3}`},
+		{"a = [1]\n b = a\n b[0] = 2\n a", "[2]"},
+		{"a = [1]\n f = (){ a[0] = 5 }\n f()\n a", "[5]"},
+		{"x = 1\n x += 2\n x", "3"},
+		{"x = 10\n x -= 3\n x", "7"},
+		{"x = 3\n x *= 4\n x", "12"},
+		{"x = 7\n x /= 2\n x", "3"},
+		{"x = 7\n x %= 4\n x", "3"},
+		{"x = 1.5\n x *= 2\n x", "3.0"},
+		{"a = [1, 2]\n a[1] += 10\n a", "[1, 12]"},
+		{"h = {\"n\": 1}\n h[\"n\"] *= 5\n h", `{"n": 5}`},
+		{"i = 0\n a = [0, 0]\n next = (){ i = i + 1\n i }\n a[next()] += 1\n a", "[0, 1]"},
+		{"a = [1, 2, 3]\n a[3] = 4", "index 3 out of range for array of length 3"},
+		{"s = \"abc\"\n s[0] = \"x\"", `STRING("abc") does not support index assignment`},
+		{"n = 1\n n[0] = 2", "INTEGER(1) does not support index assignment"},
+		{"h = {}\n h[[1]] = 2", "ARRAY is not hashable"},
+		{"x += 1", "x is unbouded variable"},
+		{"x = \"a\"\n x += 1", "PlusInfixOperator Expected INTEGER, FLOAT but got 'STRING'"},
+		{"x = 1\n x /= 0", "Zero division Error"},
+		{"h = {}\n h[\"a\"] += 1", `key "a" not found in hash`},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		if evaled.String() != tt.expected {
+			t.Errorf("%s: evaled.String() is not %s. got=%s", tt.input, tt.expected, evaled.String())
+		}
+	}
+}
+
+func TestRemainder(t *testing.T) {
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{"7 % 3", "1"},
+		{"-7 % 3", "-1"},
+		{"7 % -3", "1"},
+		{"(-9223372036854775807 - 1) % -1", "0"},
+		{"100000000000000000000 % 7", "2"},
+		{"7.5 % 2", "1.5"},
+		{"1 + 10 % 4 * 2", "5"},
+		{"1 % 0", "Zero division Error"},
+		{"1.0 % 0", "Zero division Error"},
+		{"\"a\" % 2", "PercentInfixOperator Expected INTEGER, FLOAT but got 'STRING'"},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		if evaled.String() != tt.expected {
+			t.Errorf("%s: evaled.String() is not %s. got=%s", tt.input, tt.expected, evaled.String())
+		}
+	}
+}
+
+func TestStruct(t *testing.T) {
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{"Point = struct(x, y)\n Point", "struct Point(x, y)"},
+		{"Point = struct(x, y)\n Point(1, 2)", "Point(x: 1, y: 2)"},
+		{"Point = struct(x, y)\n p = Point(1, \"a\")\n [p.x, p.y]", `[1, "a"]`},
+		{"Point = struct(x, y)\n Point(y: 2, x: 1)", "Point(x: 1, y: 2)"},
+		{"Line = struct(from, to)\n Point = struct(x, y)\n l = Line(Point(0, 0), Point(3, 4))\n l.to.y - l.from.y", "4"},
+		{"struct(a)(1)", "struct(a: 1)"},
+		{"Point = struct(x, y)\n Point(1, 2) == Point(1, 2)", "true"},
+		{"Point = struct(x, y)\n Point(1, [2]) == Point(1, [2])", "true"},
+		{"Point = struct(x, y)\n Point(1, 2) == Point(2, 1)", "false"},
+		{"P = struct(x)\n Q = struct(x)\n P(1) == Q(1)", "false"},
+		{"Point = struct(x, y)\n Point(1, 2) == [1, 2]", "false"},
+		{"P = struct(x)\n sort([P(3), P(1), P(2)])", "[P(x: 1), P(x: 2), P(x: 3)]"},
+		{"P = struct(x)\n map([1, 2], P)", "[P(x: 1), P(x: 2)]"},
+		{"P = struct(x)\n type(P(1))", `"STRUCT"`},
+		{"P = struct(x)\n type(P)", `"STRUCT_TYPE"`},
+		{"Point = struct(x, y)\n help(Point)", `"Point(x, y)\nCreate a Point."`},
+		{"Point = struct(x, y)\n Point(1)", "Point missing field y"},
+		{"Point = struct(x, y)\n Point(x: 1)", "Point missing field y"},
+		{"Point = struct(x, y)\n Point(y: 1)", "Point missing argument x"},
+		{"Point = struct(x, y)\n Point(1, 2, 3)", "Point need 2 arguments. but got 3"},
+		{"Point = struct(x, y)\n Point(1, 2, z: 3)", "Point got an unknown keyword argument z"},
+		{"Point = struct(x, y)\n Point(1, 2).z", "Point has no field z"},
+		{"[1].x", "ARRAY([1]) has no field x"},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		if evaled.String() != tt.expected {
+			t.Errorf("%s: evaled.String() is not %s. got=%s", tt.input, tt.expected, evaled.String())
+		}
+	}
+}
+
+func TestMethods(t *testing.T) {
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{"Point = struct(x, y)\n Point.norm = () => self.x * self.x + self.y * self.y\n Point(3, 4).norm()", "25"},
+		{"Point = struct(x, y)\n Point.add = (o) => Point(self.x + o.x, self.y + o.y)\n Point(1, 2).add(Point(3, 4))", "Point(x: 4, y: 6)"},
+		{"Point = struct(x, y)\n Point.scale = (k = 2) => Point(self.x * k, self.y * k)\n Point(1, 2).scale()", "Point(x: 2, y: 4)"},
+		{"Point = struct(x, y)\n Point.scale = (k) => Point(self.x * k, self.y * k)\n Point(1, 2).scale(k: 3).scale(2)", "Point(x: 6, y: 12)"},
+		{"Point = struct(x, y)\n p = Point(1, 2)\n Point.sum = () => self.x + self.y\n p.sum()", "3"},
+		{"Point = struct(x, y)\n p = Point(1, 2)\n p.x = 10\n p.y += 1\n p", "Point(x: 10, y: 3)"},
+		{"Counter = struct(n)\n Counter.inc = (){ self.n += 1\n self }\n Counter(0).inc().inc().n", "2"},
+		{"Point = struct(x, y)\n Point.x2 = () => self.x * 2\n f = Point(1, 2).x2\n f()", "self is unbouded variable"},
+		{"obj = {\"name\": \"yokan\"}\n obj.greet = () => format(\"I am %s\", self.name)\n obj.greet()", `"I am yokan"`},
+		{"obj = {\"n\": 1}\n obj.n", "1"},
+		{"obj = {\"n\": 1}\n obj.n = 2\n obj.m = 3\n obj", `{"n": 2, "m": 3}`},
+		{"obj = {\"f\": len}\n obj.f([1, 2])", "2"},
+		{"animal = {\"sound\": \"...\"}\n animal.speak = () => format(\"%s says %s\", self.name, self.sound)\n dog = create(animal, {\"name\": \"pochi\", \"sound\": \"wan\"})\n dog.speak()", `"pochi says wan"`},
+		{"animal = {\"sound\": \"...\"}\n animal.speak = () => self.sound\n fish = create(animal)\n fish.speak()", `"..."`},
+		{"base = {\"a\": 1}\n mid = create(base)\n top = create(mid, {\"b\": 2})\n [top.a, top.b]", "[1, 2]"},
+		{"base = {\"a\": 1}\n child = create(base)\n base.a = 5\n child.a", "5"},
+		{"base = {\"a\": 1}\n child = create(base)\n child.a = 2\n [base.a, child.a]", "[1, 2]"},
+		{"base = {\"a\": 1}\n child = create(base)\n child", "{}"},
+		{"base = {\"a\": 1}\n proto(create(base)) == base", "true"},
+		{"proto({})", "null"},
+		{"Point = struct(x, y)\n Point(1, 2).norm()", "Point has no method norm"},
+		{"{\"a\": 1}.f()", "HASH has no method f"},
+		{"[1, 2].len()", "ARRAY([1, 2]) has no method len"},
+		{"Point = struct(x, y)\n Point.make()", "struct Point has no method make"},
+		{"{\"a\": 1}.b", "HASH has no field b"},
+		{"Point = struct(x, y)\n p = Point(1, 2)\n p.z = 3", "Point has no field z"},
+		{"x = 1\n x.y = 2", "INTEGER(1) does not support member assignment"},
+		{"obj = {\"n\": 1}\n obj.n()", "INTEGER(1) is not a function"},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		if evaled.String() != tt.expected {
+			t.Errorf("%s: evaled.String() is not %s. got=%s", tt.input, tt.expected, evaled.String())
+		}
+	}
+}
+
+func TestEnum(t *testing.T) {
+	shape := "enum Shape { Circle(r), Rect(w, h), Empty }\n "
+	area := shape + `area = s => match (s) {
+		Circle(r) => 3 * r * r
+		Rect(w, h) => w * h
+		Empty => 0
+	}
+	`
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{shape + "Shape", "enum Shape { Circle(r), Rect(w, h), Empty }"},
+		{shape + "Circle(2)", "Circle(r: 2)"},
+		{shape + "Rect(w: 1, h: 2)", "Rect(w: 1, h: 2)"},
+		{shape + "Empty", "Empty"},
+		{shape + "Circle", "Shape.Circle(r)"},
+		{shape + "Shape.Rect(1, 2)", "Rect(w: 1, h: 2)"},
+		{shape + "Shape.Empty == Empty", "true"},
+		{shape + "Circle(1) == Circle(1)", "true"},
+		{shape + "Circle(1) == Circle(2)", "false"},
+		{shape + "Circle(1).r", "1"},
+		{shape + "[Empty, Circle(1)]", "[Empty, Circle(r: 1)]"},
+		{area + "area(Circle(2))", "12"},
+		{area + "area(Rect(2, 3))", "6"},
+		{area + "area(Empty)", "0"},
+		{area + "map([Circle(1), Rect(1, 2), Empty], area)", "[3, 2, 0]"},
+		{shape + "match (Rect(1, 5)) { Rect(_, h) if h > 3 => \"tall\", Rect(_, _) => \"short\" }", `"tall"`},
+		{shape + "match (Circle(1)) { Rect => \"rect\", Circle => \"circle\" }", `"circle"`},
+		{shape + "match (Circle(1)) { Circle(2) => 2, Circle(1) => 1 }", "1"},
+		{shape + "match (1) { Empty => 1, _ => 2 }", "2"},
+		{"Point = struct(x, y)\n match (Point(1, 2)) { Point(x, y) => x + y }", "3"},
+		{shape + "Shape.Circle.area = () => 3 * self.r * self.r\n Circle(1).area()", "3"},
+		{shape + "Shape.Triangle", "enum Shape has no field Triangle"},
+		{shape + "match (Empty) { Circle(r, x) => 1 }", "Circle has 1 fields but pattern Circle(r, x) has 2"},
+		{shape + "match (Empty) { Empty(x) => 1 }", "Empty has no fields but pattern Empty(x) has 1"},
+		{shape + "match (Empty) { Square(x) => 1 }", "Square is unbouded variable"},
+		{shape + "X = 1\n match (Empty) { X(a) => 1 }", "INTEGER(1) in pattern X(a) is not a struct or enum variant"},
+		// 構造体や列挙型の値を指さない大文字の名前は束縛する
+		{shape + "X = 1\n match (Empty) { X => X }", "Empty"},
+		{"match (3) { N => N }", "3"},
+		{"[X, Y] = [1, 2]\n X + Y", "3"},
+		{"f = ([A, b]) { A }\n f([1, 2])", "1"},
+		{shape + "[Empty, x] = [Empty, 1]\n x", "1"},
+		{shape + "[Empty, x] = [Circle(1), 1]", "cannot destructure [Circle(r: 1), 1] with pattern [Empty, x]"},
+		{shape + "Circle()", "Circle missing field r"},
+		{shape + "Empty()", "STRUCT(Empty) is not a function"},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		if evaled.String() != tt.expected {
+			t.Errorf("%s: evaled.String() is not %s. got=%s", tt.input, tt.expected, evaled.String())
+		}
+	}
+}
+
+func TestTypeAnnotationIsIgnored(t *testing.T) {
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{"x: int = 1\n x", "1"},
+		{"x: string = 1\n x", "1"},
+		{"f = (n: int, s: string = \"a\"): string { s }\n f(1)", `"a"`},
+		{"f = (a: int, ...rest: array): array => rest\n f(1, 2, 3)", "[2, 3]"},
+		{"f = ([a, b]: array): int { a + b }\n f([1, 2])", "3"},
+		{"f = (n: int): int { n }\n f(\"a\")", `"a"`},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		if evaled.String() != tt.expected {
+			t.Errorf("%s: evaled.String() is not %s. got=%s", tt.input, tt.expected, evaled.String())
+		}
+	}
+}
+
+func TestContracts(t *testing.T) {
+	isqrt := `isqrt = (n) {
+		requires n >= 0
+		ensures result * result <= n
+		ensures (result + 1) * (result + 1) > n
+		loop = (i) => match (i * i > n) { true => i - 1, _ => loop(i + 1) }
+		loop(0)
+	}
+	`
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{isqrt + "isqrt(10)", "3"},
+		{isqrt + "isqrt(0)", "0"},
+		{isqrt + "isqrt(-1)", "contract violation: isqrt requires (n >= 0) (called with -1)"},
+		{"f = (a, b) {\n requires a < b\n b - a\n }\n f(2, 1)", "contract violation: f requires (a < b) (called with 2, 1)"},
+		{"f = (n) {\n ensures result > 0\n n\n }\n f(0)", "contract violation: f ensures (result > 0) (returned 0)"},
+		{"(() {\n requires false\n 1\n })()", "contract violation: function requires false (called with no arguments)"},
+		{"Point = struct(x)\n Point.get = () {\n requires self.x > 0\n self.x\n }\n Point(0).get()", "contract violation: Point.get requires (self.x > 0) (called with no arguments)"},
+		{"f = () {\n requires 1\n 1\n }\n f()", "requires Expected BOOLEAN but got 'INTEGER'"},
+		{"f = (n) {\n requires m > 0\n n\n }\n f(1)", "m is unbouded variable"},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		if evaled.String() != tt.expected {
+			t.Errorf("%s: evaled.String() is not %s. got=%s", tt.input, tt.expected, evaled.String())
+		}
+	}
+
+	// 調べないようにすると、条件に合わなくてもそのまま実行する
+	env := object.NewEnvironment()
+	env.SetContracts(false)
+	evaled := Eval(parse(isqrt + "isqrt(-1)"), env)
+	if evaled.String() != "-1" {
+		t.Errorf("evaled.String() is not -1 without contracts. got=%s", evaled.String())
+	}
+}
+
+func TestImport(t *testing.T) {
+	dir := t.TempDir()
+	ext := t.TempDir()
+	files := map[string]string {
+		"lib/math.yk": "util = import(\"util.yk\")\n square = x => x * x\n double = x => util.twice(x)\n Point = struct(x, y)\n",
+		"lib/util.yk": "twice = x => x * 2\n whoami = () => self\n",
+		"cycle_a.yk": "b = import(\"cycle_b.yk\")\n",
+		"cycle_b.yk": "a = import(\"cycle_a.yk\")\n",
+		"broken.yk": "x = 1 / 0\n",
+		"syntax.yk": "x = (\n",
+		"dice.yk": "roll = () => random_int(1, 1000000)\n",
+	}
+	files[filepath.Join(ext, "ext.yk")] = "hello = \"ext\"\n"
+	for name, src := range files {
+		path := name
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, name)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("YOKAN_PATH", ext)
+
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{`math = import("lib/math.yk")
+		math.square(3)`, "9"},
+		// lib/math.ykの中のimportは、lib/から探す
+		{`import("lib/math.yk").double(4)`, "8"},
+		{`import("lib/math.yk").Point(1, 2)`, "Point(x: 1, y: 2)"},
+		{`m = import("lib/math.yk")
+		m.square`, "(x) {\n\t(x * x)\n}"},
+		{`import("lib/math.yk")`, "module lib/math.yk(Point, double, square, util)"},
+		// 一度だけ評価して、同じモジュールを返す
+		{`import("lib/math.yk") == import("./lib/math.yk")`, "true"},
+		{`import("lib/math.yk").util == import("lib/util.yk")`, "true"},
+		// モジュールの関数はメソッドとして呼ばない
+		{`import("lib/util.yk").whoami()`, "self is unbouded variable"},
+		{`import("ext.yk").hello`, `"ext"`},
+		{`import("lib/math.yk").nothing`, "module lib/math.yk has no field nothing"},
+		{`import("lib/math.yk").puts`, "module lib/math.yk has no field puts"},
+		{`m = import("lib/math.yk")
+		m.x = 1`, "module lib/math.yk does not support member assignment"},
+		// seedはimportしたファイルの乱数にも効く
+		{`dice = import("dice.yk")
+		seed(42)
+		a = dice.roll()
+		seed(42)
+		a == random_int(1, 1000000)`, "true"},
+		{`import("cycle_a.yk")`, "error in cycle_b.yk: import cycle: cycle_a.yk -> cycle_b.yk -> cycle_a.yk"},
+		{`import("broken.yk")`, "error in broken.yk: Zero division Error"},
+		{`import("syntax.yk")`, "import could not parse syntax.yk: expected next token to be ')', got 'EOF' instead"},
+		{`import("missing.yk")`, "module missing.yk not found in "+dir+", "+ext},
+	}
+	for _, tt := range tests {
+		env := object.NewEnvironment()
+		env.SetFile(filepath.Join(dir, "main.yk"))
+		evaled := Eval(parse(tt.input), env)
+		if evaled.String() != tt.expected {
+			t.Errorf("%s: evaled.String() is not %s. got=%s", tt.input, tt.expected, evaled.String())
+		}
+	}
+
+	// 実行しているファイルを読み込み直すのも循環になる
+	env := object.NewEnvironment()
+	env.SetFile(filepath.Join(dir, "cycle_b.yk"))
+	evaled := Eval(parse(`import("cycle_a.yk")`), env)
+	expected := "error in cycle_a.yk: import cycle: "+filepath.Join(dir, "cycle_b.yk")+" -> cycle_a.yk -> cycle_b.yk"
+	if evaled.String() != expected {
+		t.Errorf("evaled.String() is not %s. got=%s", expected, evaled.String())
+	}
+}
+
+func TestKeywordArguments(t *testing.T) {
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{"f = (x, y){ [x, y] }\n f(y: 2, x: 1)", "[1, 2]"},
+		{"f = (x, y){ [x, y] }\n f(1, y: 2)", "[1, 2]"},
+		{"f = (a, b = 2, c = 3){ [a, b, c] }\n f(1, c: 30)", "[1, 2, 30]"},
+		{"f = (a, b = a + 1){ [a, b] }\n f(a: 5)", "[5, 6]"},
+		{"f = (a, ...rest){ [a, rest] }\n f(a: 1)", "[1, []]"},
+		{`split("a,b", sep: ",")`, `["a", "b"]`},
+		{`split(sep: ",", str: "a,b")`, `["a", "b"]`},
+		{"reduce([1, 2], (a, b){ a + b }, init: 10)", "13"},
+		{"f = (x, y){ x }\n f(1, z: 2)", "Function got an unknown keyword argument z"},
+		{"f = (x, y){ x }\n f(1, x: 2)", "Function got multiple values for argument x"},
+		{"f = (x, y){ x }\n f(y: 2)", "Function missing argument x"},
+		{"f = (x, ...rest){ x }\n f(1, rest: 2)", "Function got an unknown keyword argument rest"},
+		{`split("a,b", separator: ",")`, "split got an unknown keyword argument separator"},
+		{`split("a,b", str: ",")`, "split got multiple values for argument str"},
+		{"reduce([1, 2], init: 10)", "reduce missing argument f"},
+		{"range(step: 2)", "range missing argument start"},
+		{"f = (x){ x }\n f(x: 1/0)", "Zero division Error"},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		if evaled.String() != tt.expected {
+			t.Errorf("%s: evaled.String() is not %s. got=%s", tt.input, tt.expected, evaled.String())
+		}
+	}
+}
+
 func TestEvalInfixComparingExpressions(t *testing.T) {
 	tests := []struct {
 		input string
@@ -144,6 +828,30 @@ func TestEvalInfixComparingExpressions(t *testing.T) {
 		{"1 >= 1", true},
 		{"null == null", true},
 		{"null != null", false},
+		{"1.5 == 1.5", true},
+		{"1 == 1.0", true},
+		{"1.0 != 1", false},
+		{"1.5 == 1", false},
+		{`1.5 == "1.5"`, false},
+		{"1.5 < 2", true},
+		{"2 < 1.5", false},
+		{"1.5 <= 1.5", true},
+		{"2.5 > 2", true},
+		{"2 >= 2.0", true},
+		// nanはどの数とも大小がない
+		{"nan < 1", false},
+		{"nan <= 1", false},
+		{"nan > 1", false},
+		{"nan >= 1", false},
+		{"1 > nan", false},
+		{"1 >= nan", false},
+		{"nan > nan", false},
+		{"nan == nan", false},
+		{"inf > 1e308", true},
+		{"-inf < -1e308", true},
+		{"inf >= inf", true},
+		{"100000000000000000000 > 1.5", true},
+		{"100000000000000000000 >= 100000000000000000000", true},
 	}
 	for _, tt := range tests {
 		evaled := testEval(tt.input)
@@ -152,6 +860,133 @@ func TestEvalInfixComparingExpressions(t *testing.T) {
 }
 
 // 違うものすべてと比較するのは大変な割に得られるものが少ないので、とりあえずこのくらいにしておく
+func TestEvalEqualityAndOrdering(t *testing.T) {
+	tests := []struct {
+		input string
+		expected bool
+	} {
+		{"[1, 2] == [1, 2]", true},
+		{"[1, 2] == [1, 2.0]", true},
+		{"[1, 2] == [2, 1]", false},
+		{"[1, 2] == [1, 2, 3]", false},
+		{"[[1], []] == [[1], []]", true},
+		{"[] == []", true},
+		{"[1] != [1]", false},
+		{`[1] == "[1]"`, false},
+		{`{"a": 1, "b": [2]} == {"b": [2], "a": 1}`, true},
+		{`{"a": 1} == {"a": 2}`, false},
+		{`{"a": 1} == {"b": 1}`, false},
+		{`{"a": 1} == {"a": 1, "b": 2}`, false},
+		{`{1: "a"} == {1.0: "a"}`, true},
+		{"{} == {}", true},
+		{"f = (x){x}\n f == f", true},
+		{"(x){x} == (x){x}", false},
+		{"len == len", true},
+		{"len == split", false},
+		{"f = (x){x}\n [f, 1] == [f, 1]", true},
+		{"null == (x){x}", false},
+		{"[1, 2] < [1, 3]", true},
+		{"[1, 2] < [1, 2]", false},
+		{"[1, 2] <= [1, 2]", true},
+		{"[1] < [1, 0]", true},
+		{"[2] > [1, 9]", true},
+		{"[] >= []", true},
+		{`[1, "a"] < [1, "b"]`, true},
+		{`[1] < ["a"]`, true},
+		{`"abc" < "abd"`, true},
+		{`"b" >= "abc"`, true},
+		// 関数は同じものだけが等しく、同じ書き方の別の関数は並べても等しくならない
+		{"f = (x){x}\n [[f] <= [f], [f] >= [f]] == [true, true]", true},
+		{"f = (x){x}\n g = (x){x}\n [[f] < [g], [f] > [g]] == [false, false]", false},
+		{"f = (x){x}\n g = (x){x}\n [[f] <= [g], [f] >= [g]] == [true, true]", false},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		testBooleanObject(t, evaled, tt.expected)
+	}
+
+	f := &object.Function{ }
+	g := &object.Function{ }
+	if object.Compare(f, g) == 0 || object.Compare(f, g) != -object.Compare(g, f) {
+		t.Errorf("different functions are ordered equally. got=%d, %d", object.Compare(f, g), object.Compare(g, f))
+	}
+}
+
+func TestHashLiteral(t *testing.T) {
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{`{"a": 1, "b": [2, 3]}`, `{"a": 1, "b": [2, 3]}`},
+		{"{}", "{}"},
+		{`{1: "a", 1.0: "b"}`, `{1: "b"}`},
+		{`{true: 1, null: 2, 1.5: 3}`, "{true: 1, null: 2, 1.5: 3}"},
+		{"x = 2\n {x: x + 1}", "{2: 3}"},
+		{"{[1]: 2}", "ARRAY is not hashable"},
+		{"x = {\n \"a\": 1,\n \"b\": 2,\n}\n x", `{"a": 1, "b": 2}`},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		if evaled.String() != tt.expected {
+			t.Errorf("%s: evaled.String() is not %s. got=%s", tt.input, tt.expected, evaled.String())
+		}
+	}
+
+	// 閉じていないハッシュは構文エラーになり、評価しても落ちない
+	for _, input := range []string{"{", "x = {\n \"a\": 1\n", "{,}"} {
+		p := parser.New(lexer.New(input))
+		program := p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("%s: parser has no errors", input)
+		}
+		Eval(program, object.NewEnvironment())
+	}
+}
+
+func TestEqualityOfCyclicValues(t *testing.T) {
+	// 今は自分自身を含む配列を書けないので、直接作って確かめる
+	a := &object.Array{ }
+	a.Elements = []object.Object{&object.Integer{Value: 1}, a}
+	b := &object.Array{ }
+	b.Elements = []object.Object{&object.Integer{Value: 1}, b}
+	if !object.Equals(a, b) {
+		t.Errorf("cyclic arrays are not equal")
+	}
+	if object.Compare(a, b) != 0 {
+		t.Errorf("cyclic arrays are not ordered equally. got=%d", object.Compare(a, b))
+	}
+	h := object.NewHash()
+	h.Set(&object.String{Value: "self"}, h)
+	if !object.Equals(h, h) {
+		t.Errorf("cyclic hash is not equal to itself")
+	}
+	c := &object.Array{ }
+	c.Elements = []object.Object{&object.Integer{Value: 2}, c}
+	if object.Equals(a, c) {
+		t.Errorf("different cyclic arrays are equal")
+	}
+}
+
+func TestPrintingCyclicValues(t *testing.T) {
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{"a = [1]\n a[0] = a\n a", "[[...]]"},
+		{"a = [1, 2]\n a[1] = a\n [a, a]", "[[1, [...]], [1, [...]]]"},
+		{"h = {\"x\": 1}\n h[\"self\"] = h\n h", "{\"x\": 1, \"self\": {...}}"},
+		{"h = { }\n a = [h]\n h[\"a\"] = a\n a", "[{\"a\": [...]}]"},
+		{"Node = struct(next)\n n = Node(null)\n n.next = n\n n", "Node(next: Node(...))"},
+		{"a = [1]\n a[0] = a\n str(a)", `"[[...]]"`},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		if evaled.String() != tt.expected {
+			t.Errorf("%s: evaled.String() is not %s. got=%s", tt.input, tt.expected, evaled.String())
+		}
+	}
+}
+
 func TestTypeMisMatchError(t *testing.T) {
 	tests := []string {
 		`1 + "a"`, `1 - "a"`, `1 * "a"`, `1 / "a"`,
@@ -163,6 +998,7 @@ func TestTypeMisMatchError(t *testing.T) {
 		"1 < (1==1)", "1 <= (1==1)", "1 > (1==1)", "1 >= (1==1)",
 		"(1==1) < 1", "(1==1) <= 1", "(1==1) > 1", "(1==1) >= 1",
 		"1+(1==1)\n123",
+		`1.5 + "a"`, `"a" * 1.5`, `-"a"`, `1.5 < "a"`,
 	}
 	for _, input := range tests {
 		evaled := testEval(input)
@@ -171,6 +1007,25 @@ func TestTypeMisMatchError(t *testing.T) {
 			t.Errorf("evaled is not *object.TypeMisMatchError. got=%T", evaled)
 		}
 	}
+
+	// 比較の演算子は、その演算子の名前と左の値から報告する
+	messages := []struct {
+		input string
+		expected string
+	} {
+		{`"a" < 1`, "LTInfixOperator Expected INTEGER, FLOAT but got 'STRING'"},
+		{`"a" <= 1`, "LTEQInfixOperator Expected INTEGER, FLOAT but got 'STRING'"},
+		{`"a" > 1`, "GTInfixOperator Expected INTEGER, FLOAT but got 'STRING'"},
+		{`"a" >= 1`, "GTEQInfixOperator Expected INTEGER, FLOAT but got 'STRING'"},
+		{`"a" > null`, "GTInfixOperator Expected INTEGER, FLOAT but got 'STRING'"},
+		{`1 >= null`, "GTEQInfixOperator Expected INTEGER, FLOAT but got 'NULL'"},
+	}
+	for _, tt := range messages {
+		evaled := testEval(tt.input)
+		if evaled.String() != tt.expected {
+			t.Errorf("%s: evaled.String() is not %s. got=%s", tt.input, tt.expected, evaled.String())
+		}
+	}
 }
 
 func TestAssign(t *testing.T) {
@@ -217,6 +1072,600 @@ func TestBuildinValues(t *testing.T) {
 	}
 }
 
+func TestArrayLiteral(t *testing.T) {
+	evaled := testEval(`[1, "a", [2]]`)
+	testArrayObject(t, evaled, `[1, "a", [2]]`)
+
+	evaled = testEval("[1, 2 + (1==1)]")
+	_, ok := evaled.(*object.TypeMisMatchError)
+	if !ok {
+		t.Errorf("evaled is not *object.TypeMisMatchError. got=%T", evaled)
+	}
+}
+
+func TestStringBuildins(t *testing.T) {
+	tests := []struct {
+		input string
+		expected interface{}
+	} {
+		{`len("")`, 0},
+		{`len("abc")`, 3},
+		{`len("ようかん")`, 4},
+		{`len([1, 2])`, 2},
+		{`join(split("a,b,c", ","), "-")`, "a-b-c"},
+		{`len(split("a,b,c", ","))`, 3},
+		{`join([], ",")`, ""},
+		{`trim("  a b \n")`, "a b"},
+		{`upper("abc")`, "ABC"},
+		{`lower("AbC")`, "abc"},
+		{`replace("aXbXc", "X", "--")`, "a--b--c"},
+		{`contains("yokan", "ok")`, true},
+		{`contains("yokan", "ko")`, false},
+		{`starts_with("yokan", "yo")`, true},
+		{`starts_with("yokan", "ka")`, false},
+		{`ends_with("yokan", "an")`, true},
+		{`ends_with("yokan", "yo")`, false},
+		{`index_of("yokan", "kan")`, 2},
+		{`index_of("ようかん", "かん")`, 2},
+		{`index_of("yokan", "x")`, -1},
+		{`join(chars("あいう"), " ")`, "あ い う"},
+		{`len(chars(""))`, 0},
+		{`repeat("ab", 3)`, "ababab"},
+		{`repeat("ab", 0)`, ""},
+		{`repeat("", 4611686018427387904)`, ""},
+		{`format("%d + %d = %d", 1, 2, 3)`, "1 + 2 = 3"},
+		{`format("[%5s|%-3d|%03d]", "ab", 7, 7)`, "[   ab|7  |007]"},
+		{`format("%x %X %o %b", 255, 255, 8, 5)`, "ff FF 10 101"},
+		{`format("%q %t %v %v %%", "a", true, "s", [1])`, `"a" true s [1] %`},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaled, int64(expected))
+		case string:
+			testStringObject(t, evaled, expected)
+		case bool:
+			testBooleanObject(t, evaled, expected)
+		}
+	}
+}
+
+func TestStringBuildinsError(t *testing.T) {
+	typeMisMatch := []string {
+		`len(1)`,
+		`split("a", 1)`,
+		`join("a", ",")`,
+		`join([1, 2], ",")`,
+		`trim(1)`,
+		`upper(null)`,
+		`replace("a", "b", 1)`,
+		`contains(1, "a")`,
+		`repeat("a", "b")`,
+		`format(1)`,
+		`format("%d", "a")`,
+		`format("%s", 1)`,
+	}
+	for _, input := range typeMisMatch {
+		evaled := testEval(input)
+		_, ok := evaled.(*object.TypeMisMatchError)
+		if !ok {
+			t.Errorf("%s: evaled is not *object.TypeMisMatchError. got=%T", input, evaled)
+		}
+	}
+	other := []string {
+		`len()`,
+		`split("a")`,
+		`lower("a", "b")`,
+		`repeat("a", -1)`,
+		`repeat("ab", 4611686018427387904)`,
+		`format()`,
+		`format("%d %d", 1)`,
+		`format("%d", 1, 2)`,
+		`format("%y", 1)`,
+		`format("%")`,
+	}
+	for _, input := range other {
+		evaled := testEval(input)
+		_, ok := evaled.(*object.OtherError)
+		if !ok {
+			t.Errorf("%s: evaled is not *object.OtherError. got=%T", input, evaled)
+		}
+	}
+}
+
+func TestConversionBuildins(t *testing.T) {
+	tests := []struct {
+		input string
+		expected interface{}
+	} {
+		{`int("42")`, 42},
+		{`int(" -42 ")`, -42},
+		{`int("+7")`, 7},
+		{`int("010")`, 10},
+		{`int("0x1F")`, 31},
+		{`int("-0x10")`, -16},
+		{`int("0o17")`, 15},
+		{`int("0b101")`, 5},
+		{`int(12)`, 12},
+		{`str(int("99999999999999999999"))`, "99999999999999999999"},
+		{`str(int("-0x10000000000000000"))`, "-18446744073709551616"},
+		{`int(true)`, 1},
+		{`int(false)`, 0},
+		{`str(42)`, "42"},
+		{`str(-1)`, "-1"},
+		{`str("a")`, "a"},
+		{`str(true)`, "true"},
+		{`str(null)`, "null"},
+		{`str([1, "a"])`, `[1, "a"]`},
+		{`int(str(123)) == 123`, true},
+		{`bool(1)`, true},
+		{`bool(0)`, false},
+		{`bool("true")`, true},
+		{`bool("false")`, false},
+		{`bool(null)`, false},
+		{`bool(false)`, false},
+		{`int(3.9)`, 3},
+		{`int(-3.9)`, -3},
+		{`str(1.5)`, "1.5"},
+		{`str(2.0)`, "2.0"},
+		{`bool(0.0)`, false},
+		{`format("%.2f %e %g %f", 3.14159, 1500.0, 0.5, 2)`, "3.14 1.500000e+03 0.5 2.000000"},
+		{`str(float(3))`, "3.0"},
+		{`str(float("2.5"))`, "2.5"},
+		{`type(1)`, "INTEGER"},
+		{`type(1.5)`, "FLOAT"},
+		{`type("a")`, "STRING"},
+		{`type(true)`, "BOOLEAN"},
+		{`type(null)`, "NULL"},
+		{`type([])`, "ARRAY"},
+		{`type((){})`, "FUNCTION"},
+		{`type(puts)`, "BUILDIN"},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaled, int64(expected))
+		case string:
+			testStringObject(t, evaled, expected)
+		case bool:
+			testBooleanObject(t, evaled, expected)
+		}
+	}
+}
+
+func TestConversionBuildinsError(t *testing.T) {
+	tests := []string {
+		`int("abc")`,
+		`int("")`,
+		`int("0x")`,
+		`int("0b102")`,
+		`bool("yes")`,
+		`float("x")`,
+		`int()`,
+		`str(1, 2)`,
+		`type()`,
+	}
+	for _, input := range tests {
+		evaled := testEval(input)
+		_, ok := evaled.(*object.OtherError)
+		if !ok {
+			t.Errorf("%s: evaled is not *object.OtherError. got=%T", input, evaled)
+		}
+	}
+	tests = []string {
+		`int(null)`,
+		`int([])`,
+		`bool([])`,
+		`float(null)`,
+		`format("%f", "a")`,
+	}
+	for _, input := range tests {
+		evaled := testEval(input)
+		_, ok := evaled.(*object.TypeMisMatchError)
+		if !ok {
+			t.Errorf("%s: evaled is not *object.TypeMisMatchError. got=%T", input, evaled)
+		}
+	}
+}
+
+func TestMathBuildins(t *testing.T) {
+	tests := []struct {
+		input string
+		expected interface{}
+	} {
+		{`abs(-3)`, 3},
+		{`abs(3)`, 3},
+		{`abs(-2.5)`, 2.5},
+		{`str(abs(-9223372036854775807 - 1))`, "9223372036854775808"},
+		{`min(3, 1, 2)`, 1},
+		{`max(3, 1, 2)`, 3},
+		{`min([4, 5])`, 4},
+		{`max(1, 2.5)`, 2.5},
+		{`min(7)`, 7},
+		{`pow(2, 10)`, 1024},
+		{`str(pow(2, 100))`, "1267650600228229401496703205376"},
+		{`pow(-2, 3)`, -8},
+		{`pow(2, -1)`, 0.5},
+		{`pow(4, 0.5)`, 2.0},
+		{`pow(1.5, 2)`, 2.25},
+		{`sqrt(16)`, 4.0},
+		{`sqrt(2.25)`, 1.5},
+		{`floor(2.7)`, 2},
+		{`floor(-2.2)`, -3},
+		{`floor(5)`, 5},
+		{`ceil(2.2)`, 3},
+		{`ceil(-2.7)`, -2},
+		{`str(floor(1e20))`, "100000000000000000000"},
+		{`gcd(12, 18)`, 6},
+		{`gcd(-12, 18)`, 6},
+		{`gcd(0, 0)`, 0},
+		{`lcm(4, 6)`, 12},
+		{`lcm(-4, 6)`, 12},
+		{`lcm(0, 6)`, 0},
+		{`clamp(5, 0, 10)`, 5},
+		{`clamp(-5, 0, 10)`, 0},
+		{`clamp(15, 0, 10)`, 10},
+		{`clamp(0.5, 0, 1)`, 0.5},
+		{`PI > 3.14 == (PI < 3.15)`, true},
+		{`floor(E * 1000)`, 2718},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaled, int64(expected))
+		case float64:
+			testFloatObject(t, evaled, expected)
+		case string:
+			testStringObject(t, evaled, expected)
+		case bool:
+			testBooleanObject(t, evaled, expected)
+		}
+	}
+}
+
+func TestMathBuildinsError(t *testing.T) {
+	typeMisMatch := []string {
+		`abs("a")`,
+		`min(1, "a")`,
+		`max([1, null])`,
+		`pow("a", 1)`,
+		`sqrt(null)`,
+		`floor("1")`,
+		`gcd(1.5, 2)`,
+		`lcm(1, "a")`,
+		`clamp(1, 0, "a")`,
+	}
+	for _, input := range typeMisMatch {
+		evaled := testEval(input)
+		_, ok := evaled.(*object.TypeMisMatchError)
+		if !ok {
+			t.Errorf("%s: evaled is not *object.TypeMisMatchError. got=%T", input, evaled)
+		}
+	}
+	other := []string {
+		`abs()`,
+		`min()`,
+		`max([])`,
+		`pow(2)`,
+		`pow(2, 99999999999)`,
+		`sqrt(-1)`,
+		`floor(1, 2)`,
+		`gcd(1)`,
+		`clamp(1, 10, 0)`,
+	}
+	for _, input := range other {
+		evaled := testEval(input)
+		_, ok := evaled.(*object.OtherError)
+		if !ok {
+			t.Errorf("%s: evaled is not *object.OtherError. got=%T", input, evaled)
+		}
+	}
+}
+
+func TestRandomBuildins(t *testing.T) {
+	tests := []struct {
+		input string
+		expected bool
+	} {
+		{"r = random()\n r >= 0 == (r < 1)", true},
+		{"r = random_int(1, 3)\n r >= 1 == (r <= 3)", true},
+		{"random_int(5, 5) == 5", true},
+		{"r = random_int(0, 100000000000000000000)\n r >= 0 == (r <= 100000000000000000000)", true},
+		{"len(shuffle([1, 2, 3])) == 3", true},
+		{"choice([7]) == 7", true},
+		{"seed(42)\n a = random_int(0, 1000000)\n seed(42)\n b = random_int(0, 1000000)\n a == b", true},
+		{"seed(1)\n a = random()\n seed(1)\n random() == a", true},
+		{"seed(3)\n a = join(shuffle(chars(\"abcdefgh\")), \"\")\n seed(3)\n join(shuffle(chars(\"abcdefgh\")), \"\") == a", true},
+		{"seed(5)\n a = choice([1, 2, 3, 4, 5, 6, 7, 8, 9])\n seed(5)\n choice([1, 2, 3, 4, 5, 6, 7, 8, 9]) == a", true},
+	}
+	for _, tt := range tests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+
+	// インタプリタごとに乱数の状態が分かれていて、片方を使ってももう片方の結果は変わらない
+	alone := object.NewEnvironment()
+	Eval(parse("seed(7)"), alone)
+	expected := []string {
+		Eval(parse("random_int(0, 1000000)"), alone).String(),
+		Eval(parse("random_int(0, 1000000)"), alone).String(),
+	}
+	first := object.NewEnvironment()
+	second := object.NewEnvironment()
+	Eval(parse("seed(7)"), first)
+	Eval(parse("seed(7)"), second)
+	for _, want := range expected {
+		Eval(parse("random_int(0, 1000000)"), second)
+		got := Eval(parse("random_int(0, 1000000)"), first).String()
+		if got != want {
+			t.Errorf("random_int is not %s. got=%s", want, got)
+		}
+	}
+
+	errors := []string {
+		`random(1)`,
+		`random_int(3, 1)`,
+		`choice([])`,
+		`seed(99999999999999999999)`,
+	}
+	for _, input := range errors {
+		evaled := testEval(input)
+		_, ok := evaled.(*object.OtherError)
+		if !ok {
+			t.Errorf("%s: evaled is not *object.OtherError. got=%T", input, evaled)
+		}
+	}
+	typeMisMatch := []string {
+		`random_int(1, 2.5)`,
+		`shuffle("abc")`,
+		`choice(1)`,
+		`seed("a")`,
+	}
+	for _, input := range typeMisMatch {
+		evaled := testEval(input)
+		_, ok := evaled.(*object.TypeMisMatchError)
+		if !ok {
+			t.Errorf("%s: evaled is not *object.TypeMisMatchError. got=%T", input, evaled)
+		}
+	}
+}
+
+func TestArrayBuildins(t *testing.T) {
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{"map([1, 2, 3], (x){x*x})", "[1, 4, 9]"},
+		{"map([], (x){x})", "[]"},
+		{"map([1, -2], abs)", "[1, 2]"},
+		{"n = 10\n map([1, 2], (x){x+n})", "[11, 12]"},
+		{"filter([1, 2, 3, 4], (x){x/2*2 == x})", "[2, 4]"},
+		{"reduce([1, 2, 3, 4], (acc, x){acc+x})", "10"},
+		{"reduce([1, 2, 3], (acc, x){acc*x}, 10)", "60"},
+		{"reduce([], (acc, x){acc+x}, 0)", "0"},
+		{"each([1, 2], (x){x})", "null"},
+		{"any([1, 2, 3], (x){x > 2})", "true"},
+		{"any([], (x){true})", "false"},
+		{"all([1, 2, 3], (x){x > 0})", "true"},
+		{"all([1, 2, 3], (x){x > 1})", "false"},
+		{"find([1, 2, 3], (x){x > 1})", "2"},
+		{"find([1, 2, 3], (x){x > 5})", "null"},
+		{"sort([3, 1, 2])", "[1, 2, 3]"},
+		{"sort([2.5, 1, 2])", "[1, 2, 2.5]"},
+		{`sort(["b", "c", "a"])`, `["a", "b", "c"]`},
+		{"sort([3, 1, 2], (a, b){a > b})", "[3, 2, 1]"},
+		{"sort([[2, 1], [1, 2], [1, 1]], (a, b){ reduce(a, (x, y){x*10+y}) < reduce(b, (x, y){x*10+y}) })", "[[1, 1], [1, 2], [2, 1]]"},
+		{"sort([])", "[]"},
+		{`sort(["a", 2, null, [1], true, 1.5])`, `[null, true, 1.5, 2, "a", [1]]`},
+		{"sort([[2, 1], [1, 2], [1], [1, 1]])", "[[1], [1, 1], [1, 2], [2, 1]]"},
+		{"sort([true, false])", "[false, true]"},
+		{"xs = [3, 1, 2]\n sort(xs)\n xs", "[3, 1, 2]"},
+		{"reverse([1, 2, 3])", "[3, 2, 1]"},
+		{`reverse("ようかん")`, `"んかうよ"`},
+		{"zip([1, 2, 3], [4, 5])", "[[1, 4], [2, 5]]"},
+		{`zip([1], ["a"], [true])`, `[[1, "a", true]]`},
+		{"range(4)", "[0, 1, 2, 3]"},
+		{"range(2, 5)", "[2, 3, 4]"},
+		{"range(5, 0, -2)", "[5, 3, 1]"},
+		{"range(0)", "[]"},
+		{"range(0, 6, 2)", "[0, 2, 4]"},
+		{"len(range(0, 9223372036854775807, 1000000000000000000))", "10"},
+		{"range(-9223372036854775807-1, 9223372036854775807, 9223372036854775807)", "[-9223372036854775808, -1, 9223372036854775806]"},
+		{"range(9223372036854775807, -9223372036854775807-1, -9223372036854775807)", "[9223372036854775807, 0, -9223372036854775807]"},
+		{"flatten([1, [2, [3, [4]]], []])", "[1, 2, 3, 4]"},
+		{"a = [1]\n flatten([a, [a]])", "[1, 1]"},
+		{"a = [1]\n a[0] = a\n flatten(a)", "flatten cannot flatten an array that contains itself"},
+		{"a = [1, [2]]\n a[1][0] = a\n flatten(a)", "flatten cannot flatten an array that contains itself"},
+		{"push([1], 2, 3)", "[1, 2, 3]"},
+		{"xs = [1]\n push(xs, 2)\n xs", "[1]"},
+		{"concat([1], [], [2, 3])", "[1, 2, 3]"},
+		{"concat()", "[]"},
+		{"slice([1, 2, 3, 4], 1)", "[2, 3, 4]"},
+		{"slice([1, 2, 3, 4], 1, 3)", "[2, 3]"},
+		{"slice([1, 2, 3, 4], -2)", "[3, 4]"},
+		{"slice([1, 2, 3, 4], 3, 1)", "[]"},
+		{"slice([1, 2, 3, 4], 0, 100)", "[1, 2, 3, 4]"},
+		{`slice("ようかん", 2)`, `"かん"`},
+		{"len(range(10))", "10"},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		if evaled.String() != tt.expected {
+			t.Errorf("%s: evaled.String() is not %s. got=%s", tt.input, tt.expected, evaled.String())
+		}
+	}
+}
+
+func TestArrayBuildinsError(t *testing.T) {
+	typeMisMatch := []string {
+		"map(1, (x){x})",
+		"map([1], 1)",
+		`filter([1], (x){1})`,
+		`any([1], (x){"a"})`,
+		`sort([1, 2], (a, b){1})`,
+		`reverse(1)`,
+		`zip([1], 2)`,
+		`range("a")`,
+		`flatten(1)`,
+		`push(1, 2)`,
+		`concat([1], 2)`,
+		`slice([1], "a")`,
+		`map([1, "a"], (x){x+1})`,
+	}
+	for _, input := range typeMisMatch {
+		evaled := testEval(input)
+		_, ok := evaled.(*object.TypeMisMatchError)
+		if !ok {
+			t.Errorf("%s: evaled is not *object.TypeMisMatchError. got=%T", input, evaled)
+		}
+	}
+	other := []string {
+		"map([1])",
+		"reduce([], (a, b){a})",
+		"reduce([1], (a, b){a}, 1, 2)",
+		"range(1, 2, 0)",
+		"range()",
+		"zip()",
+		"push([1])",
+		"slice([1])",
+		"map([1], (a, b){a})",
+		"each([1, 0], (x){1/x})",
+	}
+	for _, input := range other {
+		evaled := testEval(input)
+		_, ok := evaled.(*object.OtherError)
+		if !ok {
+			t.Errorf("%s: evaled is not *object.OtherError. got=%T", input, evaled)
+		}
+	}
+}
+
+func TestBuildinSignature(t *testing.T) {
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{"split(\"a\")", "split need 2 arguments. but got 1"},
+		{"reduce([1])", "reduce need 2 or 3 arguments. but got 1"},
+		{"range(1, 2, 3, 4)", "range need 1 to 3 arguments. but got 4"},
+		{"zip()", "zip need at least 1 arguments. but got 0"},
+		{"gets(1)", "gets need 0 arguments. but got 1"},
+		{"if(1, 2, 3)", "if(cond) Expected BOOLEAN but got 'INTEGER'"},
+		{"pow(2, \"a\")", "pow(exp) Expected INTEGER, FLOAT but got 'STRING'"},
+		{"concat([1], [2], 3)", "concat(arrs) Expected ARRAY but got 'INTEGER'"},
+		{"help(split)", "\"split(str: STRING, sep: STRING)\\nSplit str by sep into an array of strings.\""},
+		{"help(reduce)", "\"reduce(arr: ARRAY, f: FUNCTION|BUILDIN|STRUCT_TYPE, init?)\\nFold arr from the left with f(acc, x). Without init, the first element is used.\""},
+		{"help(push)", "\"push(arr: ARRAY, value, values...)\\nReturn a new array with values appended to arr. arr itself is not changed.\""},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		if evaled.String() != tt.expected {
+			t.Errorf("%s: evaled.String() is not %s. got=%s", tt.input, tt.expected, evaled.String())
+		}
+	}
+}
+
+func TestBuildinSignatures(t *testing.T) {
+	env := object.NewEnvironment()
+	signatures := object.BuildinSignatures(env)
+	names := map[string]bool{ }
+	for i, sig := range signatures {
+		if i > 0 && signatures[i-1].Name >= sig.Name {
+			t.Errorf("signatures are not sorted. %s is after %s", sig.Name, signatures[i-1].Name)
+		}
+		if sig.Doc == "" {
+			t.Errorf("%s has no doc", sig.Name)
+		}
+		names[sig.Name] = true
+	}
+	for _, name := range []string{"puts", "split", "int", "map", "eval", "random_int"} {
+		if !names[name] {
+			t.Errorf("signature of %s is not listed", name)
+		}
+	}
+}
+
+func TestContextBuildins(t *testing.T) {
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{"apply((a, b){a-b}, [5, 3])", "2"},
+		{"apply(max, [1, 3, 2])", "3"},
+		{"try((){1/0})", "null"},
+		{"try((){1/0}, (e){e})", `"Zero division Error"`},
+		{"try((){12}, (e){e})", "12"},
+		{`try((){error("boom")}, (e){format("caught %s", e)})`, `"caught boom"`},
+		{`try((){ map([1, 0], (x){10/x}) }, (e){e})`, `"Zero division Error"`},
+		{`eval("1 + 2")`, "3"},
+		{"x = 10\n eval(\"x * 2\")", "20"},
+		{"eval(\"y = 5\")\n y", "5"},
+		{"f = (){ z = 1\n eval(\"z + 1\") }\n f()", "2"},
+		{`assert(1 == 1)`, "null"},
+		{`try((){assert(1 == 2)}, (e){e})`, `"assertion failed at 1:14"`},
+		{"try((){assert(1 == 2, \"one is not two\")}, (e){e})", `"assertion failed at 1:14: one is not two"`},
+		{"\n  assert(false)", "assertion failed at 2:9"},
+	}
+	for _, tt := range tests {
+		evaled := testEval(tt.input)
+		if evaled.String() != tt.expected {
+			t.Errorf("%s: evaled.String() is not %s. got=%s", tt.input, tt.expected, evaled.String())
+		}
+	}
+
+	errors := []string {
+		`apply(1, [])`,
+		`apply(puts, 1)`,
+		`try(1)`,
+		`error(1)`,
+		`eval(1)`,
+		`assert(1)`,
+	}
+	for _, input := range errors {
+		evaled := testEval(input)
+		_, ok := evaled.(*object.TypeMisMatchError)
+		if !ok {
+			t.Errorf("%s: evaled is not *object.TypeMisMatchError. got=%T", input, evaled)
+		}
+	}
+	others := []string {
+		`error("boom")`,
+		`eval("1 +* 2")`,
+		`eval("0x")`,
+		`try()`,
+		`gets(1)`,
+	}
+	for _, input := range others {
+		evaled := testEval(input)
+		_, ok := evaled.(*object.OtherError)
+		if !ok {
+			t.Errorf("%s: evaled is not *object.OtherError. got=%T", input, evaled)
+		}
+	}
+}
+
+func TestInputAndOutput(t *testing.T) {
+	env := object.NewEnvironment()
+	var out bytes.Buffer
+	env.SetOutput(&out)
+	env.SetInput(bufio.NewReader(strings.NewReader("12\r\nabc\nlast")))
+
+	input := `
+		a = int(gets())
+		puts(a * 2, "\n", gets(), "\n")
+		puts(gets(), [1], gets())
+		f = (){ puts("in function") }
+		each([1], (x){ f() })
+	`
+	Eval(parse(input), env)
+	expected := "24\n\nabc\nlast[1]\nnull\nin function"
+	if out.String() != expected {
+		t.Errorf("output is not %q. got=%q", expected, out.String())
+	}
+}
+
 func TestOtherError(t *testing.T) {
 	tests := []string {
 		"1 / 0",
@@ -244,6 +1693,45 @@ func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
 	return true
 }
 
+func testFloatObject(t *testing.T, obj object.Object, expected float64) bool {
+	result, ok := obj.(*object.Float)
+	if !ok {
+		t.Errorf("obj is not *object.Float. got=%T(%s) (want=%g)", obj, obj.String(), expected)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%g, want=%g", result.Value, expected)
+		return false
+	}
+	return true
+}
+
+func testStringObject(t *testing.T, obj object.Object, expected string) bool {
+	result, ok := obj.(*object.String)
+	if !ok {
+		t.Errorf("obj is not *object.String. got=%T(%s) (want=%q)", obj, obj.String(), expected)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%q, want=%q", result.Value, expected)
+		return false
+	}
+	return true
+}
+
+func testArrayObject(t *testing.T, obj object.Object, expected string) bool {
+	result, ok := obj.(*object.Array)
+	if !ok {
+		t.Errorf("obj is not *object.Array. got=%T(%s) (want=%s)", obj, obj.String(), expected)
+		return false
+	}
+	if result.String() != expected {
+		t.Errorf("object has wrong value. got=%s, want=%s", result.String(), expected)
+		return false
+	}
+	return true
+}
+
 func testBooleanObject(t *testing.T, obj object.Object, expected bool) bool {
 	result, ok := obj.(*object.Boolean)
 	if !ok {
@@ -258,9 +1746,12 @@ func testBooleanObject(t *testing.T, obj object.Object, expected bool) bool {
 }
 
 func testEval(input string) object.Object {
+	env := object.NewEnvironment()
+	return Eval(parse(input), env)
+}
+
+func parse(input string) *ast.Program {
 	l := lexer.New(input)
 	p := parser.New(l)
-	prog := p.ParseProgram()
-	env := object.NewEnvironment()
-	return Eval(prog, env)
+	return p.ParseProgram()
 }
@@ -0,0 +1,110 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"yokan/ast"
+	"yokan/object"
+)
+
+func evalIndex(left object.Object, index object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		i, err := arrayIndex("array", index, len(left.Elements))
+		if err != nil { return err }
+		return left.Elements[i]
+	case *object.String:
+		runes := []rune(left.Value)
+		i, err := arrayIndex("string", index, len(runes))
+		if err != nil { return err }
+		return &object.String{Value: string(runes[i])}
+	case *object.Hash:
+		if _, err := object.NewHashKey(index); err != nil { return err }
+		val, ok := left.Get(index)
+		if !ok {
+			return &object.OtherError{Msg: fmt.Sprintf("key %s not found in hash", index.String())}
+		}
+		return val
+	}
+	return &object.OtherError{Msg: fmt.Sprintf("%s(%s) is not indexable", left.Type(), left.String())}
+}
+
+// 負の添字は後ろから数える
+func arrayIndex(name string, index object.Object, length int) (int, object.Object) {
+	switch index := index.(type) {
+	case *object.Integer:
+		i := index.Value
+		if i < 0 {
+			i += int64(length)
+		}
+		if 0 <= i && i < int64(length) {
+			return int(i), nil
+		}
+	case *object.BigInteger:
+	default:
+		return 0, &object.TypeMisMatchError{Name: name+" index", Expected: object.INTEGER_OBJ, Got: index}
+	}
+	return 0, &object.OtherError{Msg: fmt.Sprintf("index %s out of range for %s of length %d", index.String(), name, length)}
+}
+
+// 配列とハッシュは中身を書き換える。同じものを指している変数からも変わって見える
+func setIndex(left object.Object, index object.Object, value object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		i, err := arrayIndex("array", index, len(left.Elements))
+		if err != nil { return err }
+		left.Elements[i] = value
+		return nil
+	case *object.Hash:
+		return left.Set(index, value)
+	}
+	return &object.OtherError{Msg: fmt.Sprintf("%s(%s) does not support index assignment", left.Type(), left.String())}
+}
+
+func evalIndexAssign(node *ast.IndexAssign, env *object.Environment) object.Object {
+	left := Eval(node.Target.Left, env)
+	if isError(left) { return left }
+	index := Eval(node.Target.Index, env)
+	if isError(index) { return index }
+	val := Eval(node.Value, env)
+	if isError(val) { return val }
+	if err := setIndex(left, index, val); err != nil { return err }
+	return &object.ReturnValueOsStatement{ }
+}
+
+// arr[f()] += 1 のf()は一度だけ評価する
+func evalCompoundAssign(node *ast.CompoundAssign, env *object.Environment) object.Object {
+	switch target := node.Target.(type) {
+	case *ast.Identifier:
+		current := Eval(target, env)
+		if isError(current) { return current }
+		val := Eval(node.Value, env)
+		if isError(val) { return val }
+		result := evalInfixExpression(current, node.Operator, val)
+		if isError(result) { return result }
+		env.Set(target.Name, result)
+	case *ast.IndexExpression:
+		left := Eval(target.Left, env)
+		if isError(left) { return left }
+		index := Eval(target.Index, env)
+		if isError(index) { return index }
+		current := evalIndex(left, index)
+		if isError(current) { return current }
+		val := Eval(node.Value, env)
+		if isError(val) { return val }
+		result := evalInfixExpression(current, node.Operator, val)
+		if isError(result) { return result }
+		if err := setIndex(left, index, result); err != nil { return err }
+	case *ast.MemberExpression:
+		obj := Eval(target.Object, env)
+		if isError(obj) { return obj }
+		current := evalMember(obj, target.Member.Name)
+		if isError(current) { return current }
+		val := Eval(node.Value, env)
+		if isError(val) { return val }
+		result := evalInfixExpression(current, node.Operator, val)
+		if isError(result) { return result }
+		if err := setMember(obj, target.Member.Name, result); err != nil { return err }
+	}
+	return &object.ReturnValueOsStatement{ }
+}
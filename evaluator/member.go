@@ -0,0 +1,98 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"yokan/ast"
+	"yokan/object"
+)
+
+func evalMember(obj object.Object, name string) object.Object {
+	val, ok := lookupMember(obj, name)
+	if !ok {
+		return &object.OtherError{Msg: fmt.Sprintf("%s has no field %s", receiverName(obj), name)}
+	}
+	return val
+}
+
+// 構造体はフィールド、型のメソッドの順に、ハッシュは文字列のキーをプロトタイプまでたどって探す
+func lookupMember(obj object.Object, name string) (object.Object, bool) {
+	switch obj := obj.(type) {
+	case *object.Struct:
+		return obj.Lookup(name)
+	case *object.StructType:
+		method, ok := obj.Methods[name]
+		return method, ok
+	case *object.Hash:
+		return obj.Lookup(&object.String{Value: name})
+	case *object.EnumType:
+		variant, ok := obj.Variants[name]
+		return variant, ok
+	case *object.Module:
+		return obj.Get(name)
+	}
+	return nil, false
+}
+
+// エラーメッセージで受け手を指す名前
+func receiverName(obj object.Object) string {
+	switch obj := obj.(type) {
+	case *object.Struct:
+		return obj.Def.Name
+	case *object.StructType:
+		return "struct "+obj.Name
+	case *object.EnumType:
+		return "enum "+obj.Name
+	case *object.Hash:
+		return string(obj.Type())
+	case *object.Module:
+		return "module "+obj.Name
+	}
+	return fmt.Sprintf("%s(%s)", obj.Type(), obj.String())
+}
+
+// obj.method(args) は、見つけた関数をselfにobjを束縛して呼ぶ
+// モジュールの関数はメソッドではないので、そのまま呼ぶ
+func evalMethodCall(node *ast.FunctionCalling, me *ast.MemberExpression, env *object.Environment) object.Object {
+	receiver := Eval(me.Object, env)
+	if isError(receiver) { return receiver }
+	method, ok := lookupMember(receiver, me.Member.Name)
+	if !ok {
+		return &object.OtherError{Msg: fmt.Sprintf("%s has no method %s", receiverName(receiver), me.Member.Name)}
+	}
+	args, err := evalArguments(method, node.Arguments, env)
+	if err != nil { return err }
+	if fn, ok := method.(*object.Function); ok {
+		if _, ok := receiver.(*object.Module); ok {
+			return callFunction(fn, args, nil)
+		}
+		return callFunction(fn, args, receiver)
+	}
+	return applyFunction(method, args, newContext(node.Token.Pos, env))
+}
+
+func evalMemberAssign(node *ast.MemberAssign, env *object.Environment) object.Object {
+	obj := Eval(node.Target.Object, env)
+	if isError(obj) { return obj }
+	val := Eval(node.Value, env)
+	if isError(val) { return val }
+	if err := setMember(obj, node.Target.Member.Name, val); err != nil { return err }
+	return &object.ReturnValueOsStatement{ }
+}
+
+// 構造体の値はあるフィールドだけ書き換えられる。型に代入するとメソッドになる
+func setMember(obj object.Object, name string, val object.Object) object.Object {
+	switch obj := obj.(type) {
+	case *object.Struct:
+		if !obj.Set(name, val) {
+			return &object.OtherError{Msg: fmt.Sprintf("%s has no field %s", obj.Def.Name, name)}
+		}
+		return nil
+	case *object.StructType:
+		obj.Methods[name] = val
+		return nil
+	case *object.Hash:
+		return obj.Set(&object.String{Value: name}, val)
+	}
+	return &object.OtherError{Msg: fmt.Sprintf("%s does not support member assignment", receiverName(obj))}
+}
@@ -0,0 +1,168 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"yokan/ast"
+	"yokan/object"
+)
+
+func evalMatchExpression(node *ast.MatchExpression, env *object.Environment) object.Object {
+	value := Eval(node.Value, env)
+	if isError(value) { return value }
+	for _, arm := range node.Arms {
+		armEnv := object.NewInferitEnvironment(env)
+		ok, err := matchPattern(arm.Pattern, value, armEnv)
+		if err != nil { return err }
+		if !ok { continue }
+		if arm.Guard != nil {
+			guard := Eval(arm.Guard, armEnv)
+			if isError(guard) { return guard }
+			b, isBool := guard.(*object.Boolean)
+			if !isBool {
+				return &object.TypeMisMatchError{Name: "match guard", Expected: object.BOOLEAN_OBJ, Got: guard}
+			}
+			if !b.Value { continue }
+		}
+		return Eval(arm.Body, armEnv)
+	}
+	return &object.OtherError{Msg: fmt.Sprintf("no pattern matched %s in match at %s", value.String(), node.Token.Pos)}
+}
+
+// 列挙型の名前と、それぞれの値を作る関数(カッコのない値はその値)を束縛する
+func evalEnumStatement(node *ast.EnumStatement, env *object.Environment) object.Object {
+	et := object.NewEnumType(node.Name.Name)
+	for _, v := range node.Variants {
+		var fields []string
+		if v.Fields != nil {
+			fields = []string{ }
+			for _, f := range v.Fields {
+				fields = append(fields, f.Name)
+			}
+		}
+		env.Set(v.Name.Name, et.AddVariant(v.Name.Name, fields))
+	}
+	env.Set(node.Name.Name, et)
+	return &object.ReturnValueOsStatement{ }
+}
+
+func evalDestructuringAssign(node *ast.DestructuringAssign, env *object.Environment) object.Object {
+	val := Eval(node.Value, env)
+	if isError(val) { return val }
+	err := destructure(node.Pattern, val, env)
+	if err != nil { return err }
+	return &object.ReturnValueOsStatement{ }
+}
+
+// 分割代入と分割して受け取る引数で使う。一致しなければエラーにして、envには何も束縛しない
+func destructure(pattern ast.Pattern, value object.Object, env *object.Environment) object.Object {
+	tmp := object.NewInferitEnvironment(env)
+	ok, err := matchPattern(pattern, value, tmp)
+	if err != nil { return err }
+	if !ok {
+		return &object.OtherError{Msg: fmt.Sprintf("cannot destructure %s with pattern %s", value.String(), pattern.String())}
+	}
+	for _, name := range pattern.Names() {
+		val, _ := tmp.Get(name)
+		env.Set(name, val)
+	}
+	return nil
+}
+
+// 一致すれば、パターンの中の名前をenvに束縛する
+// 一致しなかったときにenvに残った束縛は、呼び出し側で捨てる
+func matchPattern(pattern ast.Pattern, value object.Object, env *object.Environment) (bool, object.Object) {
+	switch pattern := pattern.(type) {
+	case *ast.WildcardPattern:
+		return true, nil
+	case *ast.BindingPattern:
+		// 構造体の型や列挙型のカッコのない値を指す大文字の名前は、束縛せずにその値かどうかを調べる
+		if isConstructor(pattern.Name.Name, env) {
+			return matchConstructorPattern(&ast.ConstructorPattern{Name: pattern.Name}, value, env)
+		}
+		env.Set(pattern.Name.Name, value)
+		return true, nil
+	case *ast.LiteralPattern:
+		lit := Eval(pattern.Value, env)
+		if isError(lit) { return false, lit }
+		return object.Equals(lit, value), nil
+	case *ast.ArrayPattern:
+		arr, ok := value.(*object.Array)
+		if !ok { return false, nil }
+		if len(arr.Elements) < len(pattern.Elements) || pattern.Rest == nil && len(arr.Elements) != len(pattern.Elements) {
+			return false, nil
+		}
+		for i, e := range pattern.Elements {
+			ok, err := matchPattern(e, arr.Elements[i], env)
+			if err != nil || !ok { return ok, err }
+		}
+		if pattern.Rest != nil {
+			rest := []object.Object{ }
+			rest = append(rest, arr.Elements[len(pattern.Elements):]...)
+			return matchPattern(pattern.Rest, &object.Array{Elements: rest}, env)
+		}
+		return true, nil
+	case *ast.HashPattern:
+		hash, ok := value.(*object.Hash)
+		if !ok { return false, nil }
+		for i, keyNode := range pattern.Keys {
+			key := Eval(keyNode, env)
+			if isError(key) { return false, key }
+			v, ok := hash.Get(key)
+			if !ok { return false, nil }
+			ok, err := matchPattern(pattern.Values[i], v, env)
+			if err != nil || !ok { return ok, err }
+		}
+		return true, nil
+	case *ast.ConstructorPattern:
+		return matchConstructorPattern(pattern, value, env)
+	}
+	return false, &object.OtherError{Msg: fmt.Sprintf("%T is not yet implemented", pattern)}
+}
+
+func isConstructor(name string, env *object.Environment) bool {
+	if !('A' <= name[0] && name[0] <= 'Z') {
+		return false
+	}
+	switch obj, _ := env.Get(name); obj := obj.(type) {
+	case *object.StructType:
+		return true
+	case *object.Struct:
+		return obj.Def.Enum != nil && obj.Def.Enum.IsUnit(obj.Def.Name)
+	}
+	return false
+}
+
+// 名前はパターンを試すときの環境で探す
+func matchConstructorPattern(pattern *ast.ConstructorPattern, value object.Object, env *object.Environment) (bool, object.Object) {
+	name := pattern.Name.Name
+	constructor, ok := env.Get(name)
+	if !ok {
+		return false, &object.OtherError{Msg: fmt.Sprintf("%s is unbouded variable", name)}
+	}
+	var def *object.StructType
+	switch c := constructor.(type) {
+	case *object.StructType:
+		def = c
+	case *object.Struct:
+		// カッコのない列挙型の値
+		if pattern.Fields != nil && len(pattern.Fields) != 0 {
+			return false, &object.OtherError{Msg: fmt.Sprintf("%s has no fields but pattern %s has %d", name, pattern.String(), len(pattern.Fields))}
+		}
+		def = c.Def
+	default:
+		return false, &object.OtherError{Msg: fmt.Sprintf("%s(%s) in pattern %s is not a struct or enum variant", c.Type(), c.String(), pattern.String())}
+	}
+	if pattern.Fields != nil && len(pattern.Fields) != len(def.Fields) {
+		return false, &object.OtherError{Msg: fmt.Sprintf("%s has %d fields but pattern %s has %d", name, len(def.Fields), pattern.String(), len(pattern.Fields))}
+	}
+	s, ok := value.(*object.Struct)
+	if !ok || s.Def != def {
+		return false, nil
+	}
+	for i, field := range pattern.Fields {
+		ok, err := matchPattern(field, s.Values[i], env)
+		if err != nil || !ok { return ok, err }
+	}
+	return true, nil
+}
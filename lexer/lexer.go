@@ -1,6 +1,8 @@
 package lexer
 
 import (
+	"fmt"
+	"strings"
 	"yokan/token"
 )
 
@@ -9,20 +11,36 @@ type Lexer struct {
 	position int
 	readPosition int
 	ch byte
+	errors []string
+	// chの位置
+	line int
+	column int
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{input: input, line: 1}
 	l.readChar()
 	return l
 }
 
+func (l *Lexer) Errors() []string {
+	return l.errors
+}
+
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line += 1
+		l.column = 0
+	}
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
 	} else {
 		l.ch = l.input[l.readPosition]
 	}
+	// 列はバイト数ではなく文字数で数えるので、UTF-8の2バイト目以降は数えない
+	if l.ch&0xC0 != 0x80 {
+		l.column += 1
+	}
 	l.position = l.readPosition
 	l.readPosition += 1
 }
@@ -54,31 +72,42 @@ func (l *Lexer) skipLines() {
 }
 
 func (l *Lexer) NextToken() token.Token {
+	l.skipWhiteSpaces()
+	pos := token.Position{Line: l.line, Column: l.column}
+	tok := l.readToken()
+	tok.Pos = pos
+	return tok
+}
+
+func (l *Lexer) readToken() token.Token {
 	var tok token.Token
 
-	l.skipWhiteSpaces()
-	
 	switch l.ch {
 	case '=':
 		if l.peekChar() == '=' {
 			l.readChar()
 			tok = token.Token{Type: token.EQ, Literal: "=="}
+		} else if l.peekChar() == '>' {
+			l.readChar()
+			tok = token.Token{Type: token.ARROW, Literal: "=>"}
 		} else {
 			tok = newToken(token.ASSIGN, l.ch)
 		}
 	case '+':
-		tok = newToken(token.PLUS, l.ch)
+		tok = l.newAssignableToken(token.PLUS, token.PLUS_ASSIGN)
 	case '-':
-		tok = newToken(token.MINUS, l.ch)
+		tok = l.newAssignableToken(token.MINUS, token.MINUS_ASSIGN)
 	case '*':
-		tok = newToken(token.STAR, l.ch)
+		tok = l.newAssignableToken(token.STAR, token.STAR_ASSIGN)
 	case '/':
 		if l.peekChar() == '/' {
 			l.skipLines()
 			tok = newToken(token.NEWLINE, '\n')
 		} else {
-			tok = newToken(token.SLASH, l.ch)
+			tok = l.newAssignableToken(token.SLASH, token.SLASH_ASSIGN)
 		}
+	case '%':
+		tok = l.newAssignableToken(token.PERCENT, token.PERCENT_ASSIGN)
 	case '!':
 		if l.peekChar() == '=' {
 			l.readChar()
@@ -100,6 +129,13 @@ func (l *Lexer) NextToken() token.Token {
 		} else {
 			tok = newToken(token.GT, l.ch)
 		}
+	case '|':
+		if l.peekChar() == '>' {
+			l.readChar()
+			tok = token.Token{Type: token.PIPE, Literal: "|>"}
+		} else {
+			tok = newToken(token.ILLEGAL, l.ch)
+		}
 	case '[':
 		tok = newToken(token.LBRACK, l.ch)
 	case ']':
@@ -110,6 +146,16 @@ func (l *Lexer) NextToken() token.Token {
 		return tok
 	case ',':
 		tok = newToken(token.COMMA, l.ch)
+	case ':':
+		tok = newToken(token.COLON, l.ch)
+	case '.':
+		if l.peekChar() == '.' && l.readPosition+1 < len(l.input) && l.input[l.readPosition+1] == '.' {
+			l.readChar()
+			l.readChar()
+			tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+		} else {
+			tok = newToken(token.DOT, l.ch)
+		}
 	case '(':
 		tok = newToken(token.LPAREN, l.ch)
 	case ')':
@@ -124,9 +170,11 @@ func (l *Lexer) NextToken() token.Token {
 		tok = token.Token{Type: token.EOF, Literal: "EOF"}
 	default:
 		if isDigit(l.ch) {
-			return token.Token{Type: token.INT, Literal: l.readDigits()}
+			tokenType, literal := l.readDigits()
+			return token.Token{Type: tokenType, Literal: literal}
 		} else if isLetter(l.ch) {
-			return token.Token{Type: token.IDENT, Literal: l.readIdentifier()}
+			ident := l.readIdentifier()
+			return token.Token{Type: token.LookupIdent(ident), Literal: ident}
 		}
 		tok = newToken(token.ILLEGAL, l.ch)
 	}
@@ -147,12 +195,115 @@ func include(start byte, end byte, ch byte) bool {
 	return start <= ch && ch <= end
 }
 
-func (l *Lexer) readDigits() string {
+// 123, 1_000_000, 0x1F, 0o17, 0b101, 3.14, 1e-9, 2.5E+3 のような数を読む
+// 小数点か指数があれば浮動小数点数になる
+// 12ab や 0x のような書き間違いは、続く英数字までまとめてILLEGALにしてエラーを記録する
+func (l *Lexer) readDigits() (token.TokenType, string) {
 	pos := l.position
-	for isDigit(l.ch) {
+	if l.ch == '0' && strings.IndexByte("xXoObB", l.peekChar()) >= 0 {
+		return l.readPrefixedDigits()
+	}
+	tokenType := token.TokenType(token.INT)
+	l.skipDigits()
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		tokenType = token.FLOAT
+		l.readChar()
+		l.skipDigits()
+	}
+	if l.ch == 'e' || l.ch == 'E' {
+		next := l.peekChar()
+		if isDigit(next) || (next == '+' || next == '-') && l.readPosition+1 < len(l.input) && isDigit(l.input[l.readPosition+1]) {
+			tokenType = token.FLOAT
+			l.readChar()
+			if l.ch == '+' || l.ch == '-' {
+				l.readChar()
+			}
+			l.skipDigits()
+		}
+	}
+	if isLetter(l.ch) {
+		l.skipAlphanumerics()
+		literal := l.input[pos:l.position]
+		return l.illegalNumber(literal, fmt.Sprintf("unexpected character %q", firstLetter(literal)))
+	}
+	literal := l.input[pos:l.position]
+	for _, digits := range strings.FieldsFunc(literal, func(r rune) bool { return strings.ContainsRune(".eE+-", r) }) {
+		if msg := checkUnderscores(digits); msg != "" {
+			return l.illegalNumber(literal, msg)
+		}
+	}
+	return tokenType, literal
+}
+
+func (l *Lexer) readPrefixedDigits() (token.TokenType, string) {
+	pos := l.position
+	l.readChar()
+	prefix := l.ch
+	l.readChar()
+	l.skipAlphanumerics()
+	literal := l.input[pos:l.position]
+	digits := literal[2:]
+
+	var isValid func(byte) bool
+	switch prefix {
+	case 'x', 'X':
+		isValid = func(ch byte) bool { return isDigit(ch) || include('a', 'f', ch) || include('A', 'F', ch) }
+	case 'o', 'O':
+		isValid = func(ch byte) bool { return include('0', '7', ch) }
+	default:
+		isValid = func(ch byte) bool { return include('0', '1', ch) }
+	}
+	if digits == "" {
+		return l.illegalNumber(literal, fmt.Sprintf("no digits after %q", literal[:2]))
+	}
+	for i := 0; i < len(digits); i++ {
+		if digits[i] != '_' && !isValid(digits[i]) {
+			return l.illegalNumber(literal, fmt.Sprintf("invalid digit %q after %q", digits[i], literal[:2]))
+		}
+	}
+	if msg := checkUnderscores(digits); msg != "" {
+		return l.illegalNumber(literal, msg)
+	}
+	return token.INT, literal
+}
+
+func (l *Lexer) illegalNumber(literal string, reason string) (token.TokenType, string) {
+	l.errors = append(l.errors, fmt.Sprintf("malformed number literal %q: %s", literal, reason))
+	return token.ILLEGAL, literal
+}
+
+// _は数字と数字の間にだけ書ける
+func checkUnderscores(digits string) string {
+	for i := 0; i < len(digits); i++ {
+		if digits[i] != '_' {
+			continue
+		}
+		if i == 0 || i == len(digits)-1 || digits[i-1] == '_' || digits[i+1] == '_' {
+			return "'_' must separate successive digits"
+		}
+	}
+	return ""
+}
+
+func firstLetter(literal string) byte {
+	for i := 0; i < len(literal); i++ {
+		if isLetter(literal[i]) && literal[i] != '_' {
+			return literal[i]
+		}
+	}
+	return literal[0]
+}
+
+func (l *Lexer) skipDigits() {
+	for isDigit(l.ch) || l.ch == '_' {
+		l.readChar()
+	}
+}
+
+func (l *Lexer) skipAlphanumerics() {
+	for isLetter(l.ch) || isDigit(l.ch) {
 		l.readChar()
 	}
-	return l.input[pos:l.position]
 }
 
 func (l *Lexer) readIdentifier() string {
@@ -186,12 +337,23 @@ func (l *Lexer) readStringLiteral() string {
 			}
 			l.readChar()
 		default:
-			literal += string(l.ch)
+			// string(l.ch)だとUTF-8のバイト列が1バイトずつ別の文字になってしまう
+			literal += l.input[l.position:l.readPosition]
 		}
 		l.readChar()
 	}
 }
 
+// + と += のように、後ろに = が付くと代入になる演算子
+func (l *Lexer) newAssignableToken(tokenType token.TokenType, assignType token.TokenType) token.Token {
+	if l.peekChar() == '=' {
+		ch := l.ch
+		l.readChar()
+		return token.Token{Type: assignType, Literal: string(ch)+"="}
+	}
+	return newToken(tokenType, l.ch)
+}
+
 func newToken(tokenType token.TokenType, ch byte) token.Token {
 	return token.Token{Type: tokenType, Literal: string(ch)}
 }
@@ -11,7 +11,7 @@ type TypeAndLiteral struct {
 }
 
 func TestOneCharacterKeywords(t *testing.T) {
-	input := `=+-*/,(){}<>[]`
+	input := `=+-*/,(){}<>[]:%.`
 
 	expected := []TypeAndLiteral {
 		{token.ASSIGN, "="},
@@ -28,18 +28,29 @@ func TestOneCharacterKeywords(t *testing.T) {
 		{token.GT, ">"},
 		{token.LBRACK, "["},
 		{token.RBRACK, "]"},
+		{token.COLON, ":"},
+		{token.PERCENT, "%"},
+		{token.DOT, "."},
 		{token.EOF, "EOF"},
 	}
 	checkTokens(t, input, expected)
 }
 
 func TestTwoCharacterKeywords(t *testing.T) {
-	input := "== != <= >="
+	input := "== != <= >= ... => |> += -= *= /= %="
 	expected := []TypeAndLiteral {
 		{token.EQ, "=="},
 		{token.NOTEQ, "!="},
 		{token.LTEQ, "<="},
 		{token.GTEQ, ">="},
+		{token.ELLIPSIS, "..."},
+		{token.ARROW, "=>"},
+		{token.PIPE, "|>"},
+		{token.PLUS_ASSIGN, "+="},
+		{token.MINUS_ASSIGN, "-="},
+		{token.STAR_ASSIGN, "*="},
+		{token.SLASH_ASSIGN, "/="},
+		{token.PERCENT_ASSIGN, "%="},
 		{token.EOF, "EOF"},
 	}
 	checkTokens(t, input, expected)
@@ -91,6 +102,77 @@ func TestInteger(t *testing.T) {
 	checkTokens(t, input, expected)
 }
 
+func TestFloat(t *testing.T) {
+	input := "3.14 1e-9 2.5E+3 1e3-0.5 1.e 1.x"
+	// 1.e は 1 . e として読む
+	expected := []TypeAndLiteral {
+		{token.FLOAT, "3.14"},
+		{token.FLOAT, "1e-9"},
+		{token.FLOAT, "2.5E+3"},
+		{token.FLOAT, "1e3"},
+		{token.MINUS, "-"},
+		{token.FLOAT, "0.5"},
+		{token.INT, "1"},
+		{token.DOT, "."},
+		{token.IDENT, "e"},
+		{token.INT, "1"},
+		{token.DOT, "."},
+		{token.IDENT, "x"},
+		{token.EOF, "EOF"},
+	}
+	checkTokens(t, input, expected)
+}
+
+func TestPrefixedAndSeparatedInteger(t *testing.T) {
+	input := "0x1F 0XfF 0o17 0b1010 1_000_000 0xFF_FF 1_000.000_1 0 0.5"
+	expected := []TypeAndLiteral {
+		{token.INT, "0x1F"},
+		{token.INT, "0XfF"},
+		{token.INT, "0o17"},
+		{token.INT, "0b1010"},
+		{token.INT, "1_000_000"},
+		{token.INT, "0xFF_FF"},
+		{token.FLOAT, "1_000.000_1"},
+		{token.INT, "0"},
+		{token.FLOAT, "0.5"},
+		{token.EOF, "EOF"},
+	}
+	checkTokens(t, input, expected)
+}
+
+func TestMalformedNumber(t *testing.T) {
+	tests := []struct {
+		input string
+		expectedError string
+	} {
+		{"0x", `malformed number literal "0x": no digits after "0x"`},
+		{"0b", `malformed number literal "0b": no digits after "0b"`},
+		{"0b102", `malformed number literal "0b102": invalid digit '2' after "0b"`},
+		{"0o8", `malformed number literal "0o8": invalid digit '8' after "0o"`},
+		{"0xfg", `malformed number literal "0xfg": invalid digit 'g' after "0x"`},
+		{"12ab", `malformed number literal "12ab": unexpected character 'a'`},
+		{"1e", `malformed number literal "1e": unexpected character 'e'`},
+		{"1__000", `malformed number literal "1__000": '_' must separate successive digits`},
+		{"1000_", `malformed number literal "1000_": '_' must separate successive digits`},
+		{"1_.5", `malformed number literal "1_.5": '_' must separate successive digits`},
+		{"0x_1", `malformed number literal "0x_1": '_' must separate successive digits`},
+	}
+	for _, tt := range tests {
+		l := New(tt.input + " +1")
+		tok := l.NextToken()
+		if tok.Type != token.ILLEGAL || tok.Literal != tt.input {
+			t.Errorf("%s: token is not ILLEGAL(%q). got=%s(%q)", tt.input, tt.input, tok.Type, tok.Literal)
+		}
+		// 書き間違えた部分だけを読み飛ばして、続きは普通に読める
+		if next := l.NextToken(); next.Type != token.PLUS {
+			t.Errorf("%s: next token is not PLUS. got=%s(%q)", tt.input, next.Type, next.Literal)
+		}
+		if len(l.Errors()) != 1 || l.Errors()[0] != tt.expectedError {
+			t.Errorf("%s: errors is not [%q]. got=%q", tt.input, tt.expectedError, l.Errors())
+		}
+	}
+}
+
 func TestIdentifier(t *testing.T) {
 	input := "a+bbb*CcC ddddd _eE_123e\nfff"
 	expected := []TypeAndLiteral {
@@ -108,14 +190,30 @@ func TestIdentifier(t *testing.T) {
 	checkTokens(t, input, expected)
 }
 
+func TestKeyword(t *testing.T) {
+	input := "match struct enum requires ensures matches _match"
+	expected := []TypeAndLiteral {
+		{token.MATCH, "match"},
+		{token.STRUCT, "struct"},
+		{token.ENUM, "enum"},
+		{token.REQUIRES, "requires"},
+		{token.ENSURES, "ensures"},
+		{token.IDENT, "matches"},
+		{token.IDENT, "_match"},
+		{token.EOF, "EOF"},
+	}
+	checkTokens(t, input, expected)
+}
+
 func TestString(t *testing.T) {
-	input := "\"abc\" \"\" \"\\\"\" \"\\n\\t\" \"\n\""
+	input := "\"abc\" \"\" \"\\\"\" \"\\n\\t\" \"\n\" \"ようかん\""
 	expected := []TypeAndLiteral {
 		{token.STRING, "abc"},
 		{token.STRING, ""},
 		{token.STRING, "\""},
 		{token.STRING, "\n\t"},
 		{token.STRING, "\n"},
+		{token.STRING, "ようかん"},
 		{token.EOF, "EOF"},
 	}
 	checkTokens(t, input, expected)
@@ -184,6 +282,39 @@ func TestComplexSource(t *testing.T) {
 	checkTokens(t, input, expected)
 }
 
+func TestPosition(t *testing.T) {
+	input := "a = 1\n  bb(\"よう\", 23)\n\n//c\nd"
+	expected := []struct {
+		Type token.TokenType
+		Pos token.Position
+	} {
+		{token.IDENT, token.Position{Line: 1, Column: 1}},
+		{token.ASSIGN, token.Position{Line: 1, Column: 3}},
+		{token.INT, token.Position{Line: 1, Column: 5}},
+		{token.NEWLINE, token.Position{Line: 1, Column: 6}},
+		{token.IDENT, token.Position{Line: 2, Column: 3}},
+		{token.LPAREN, token.Position{Line: 2, Column: 5}},
+		{token.STRING, token.Position{Line: 2, Column: 6}},
+		{token.COMMA, token.Position{Line: 2, Column: 10}},
+		{token.INT, token.Position{Line: 2, Column: 12}},
+		{token.RPAREN, token.Position{Line: 2, Column: 14}},
+		{token.NEWLINE, token.Position{Line: 2, Column: 15}},
+		{token.NEWLINE, token.Position{Line: 4, Column: 1}},
+		{token.IDENT, token.Position{Line: 5, Column: 1}},
+		{token.EOF, token.Position{Line: 5, Column: 2}},
+	}
+	l := New(input)
+	for i, tt := range expected {
+		tok := l.NextToken()
+		if tok.Type != tt.Type {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.Type, tok.Type)
+		}
+		if tok.Pos != tt.Pos {
+			t.Errorf("tests[%d] - position wrong. expected=%s, got=%s", i, tt.Pos, tok.Pos)
+		}
+	}
+}
+
 func checkTokens(t *testing.T, input string, expected []TypeAndLiteral) {
 	l := New(input)
 
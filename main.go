@@ -1,10 +1,93 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"io"
 	"os"
+
+	"yokan/checker"
+	"yokan/evaluator"
+	"yokan/lexer"
+	"yokan/object"
+	"yokan/parser"
 	"yokan/repl"
 )
 
 func main() {
-	repl.Start(os.Stdin, os.Stdout)
+	// 速さが必要なときは、関数の requires と ensures を調べずに実行できる
+	nocontracts := flag.Bool("nocontracts", false, "do not check requires and ensures of functions")
+	flag.Parse()
+	args := flag.Args()
+	if len(args) >= 1 && args[0] == "check" {
+		os.Exit(check(args[1:], os.Stdout))
+	}
+	if len(args) >= 1 {
+		os.Exit(run(args[0], !*nocontracts))
+	}
+	repl.Start(os.Stdin, os.Stdout, !*nocontracts)
+}
+
+// yokan FILE
+// ファイルを実行する。importはこのファイルのディレクトリから探す
+func run(path string, contracts bool) int {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	p := parser.New(lexer.New(string(src)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		for _, msg := range p.Errors() {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", path, msg)
+		}
+		return 1
+	}
+	env := object.NewEnvironment()
+	env.SetContracts(contracts)
+	env.SetFile(path)
+	if result := evaluator.Eval(program, env); result.Type() == object.ERROR_OBJ {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", path, result.String())
+		return 1
+	}
+	return 0
+}
+
+// yokan check FILE...
+// 評価せずに型の注釈を調べる。誤りがあれば1を返す
+func check(paths []string, out io.Writer) int {
+	if len(paths) == 0 {
+		fmt.Fprintln(out, "usage: yokan check FILE...")
+		return 2
+	}
+	status := 0
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(out, err)
+			status = 1
+			continue
+		}
+		p := parser.New(lexer.New(string(src)))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			for _, msg := range p.Errors() {
+				fmt.Fprintf(out, "%s: %s\n", path, msg)
+			}
+			status = 1
+			continue
+		}
+		// 注釈が合っていれば、注釈のないところも推論して調べる
+		// 型の誤りは "行:列: メッセージ" の形なので、ファイル名を前に付ける
+		errors := checker.Check(program)
+		if len(errors) == 0 {
+			errors = checker.Infer(program)
+		}
+		for _, msg := range errors {
+			fmt.Fprintf(out, "%s:%s\n", path, msg)
+			status = 1
+		}
+	}
+	return status
 }
@@ -2,35 +2,86 @@ package object
 
 import (
 	"fmt"
+	"math"
+	"math/big"
+	"sort"
 )
 
 var Buildins = map[string]Object{
 	"true": &Boolean{Value: true},
 	"false": &Boolean{Value: false},
 	"null": &Null{ },
+	"PI": &Float{Value: math.Pi},
+	"E": &Float{Value: math.E},
+	// 浮動小数点数の inf と nan を書き出したものを、そのまま読み直せるようにする
+	"inf": &Float{Value: math.Inf(1)},
+	"nan": &Float{Value: math.NaN()},
+}
+
+func init() {
+	registerBuildins(Buildins, coreBuildins)
+	registerBuildins(Buildins, stringBuildins)
+	registerBuildins(Buildins, numberBuildins)
+	registerBuildins(Buildins, arrayBuildins)
+	registerBuildins(Buildins, hashBuildins)
+	registerBuildins(Buildins, evalBuildins)
+	registerBuildins(Buildins, moduleBuildins)
+}
+
+// 名前をSignatureに入れてからstoreに登録する
+func registerBuildins(store map[string]Object, buildins map[string]*Buildin) {
+	for name, b := range buildins {
+		b.Signature.Name = name
+		store[name] = b
+	}
+}
+
+// envから見える組み込み関数の宣言を、名前順に返す
+func BuildinSignatures(env *Environment) []*Signature {
+	var signatures []*Signature
+	for _, obj := range env.root().store {
+		if b, ok := obj.(*Buildin); ok && b.Signature != nil {
+			signatures = append(signatures, b.Signature)
+		}
+	}
+	sort.Slice(signatures, func(i, j int) bool {
+		return signatures[i].Name < signatures[j].Name
+	})
+	return signatures
+}
+
+var coreBuildins = map[string]*Buildin{
 	"puts": &Buildin{
-		Fn: func(args ...Object) Object {
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "values", Variadic: true},
+			},
+			Doc: "Print values. Strings are printed as is, others are followed by a newline.",
+		},
+		ContextFn: func(ctx *Context, args ...Object) Object {
 			for _, arg := range args {
 				if arg.Type() == STRING_OBJ {
-					fmt.Print(arg.(*String).Value)
+					fmt.Fprint(ctx.Out, arg.(*String).Value)
 				} else {
-					fmt.Println(arg.String())
+					fmt.Fprintln(ctx.Out, arg.String())
 				}
 			}
 			return &Null{ }
 		},
 	},
 	"if": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "cond", Types: []ObjectType{BOOLEAN_OBJ}},
+				{Name: "then"},
+				{Name: "else"},
+			},
+			Doc: "Return then if cond is true, otherwise else.",
+		},
 		Fn: func(args ...Object) Object {
-			if len(args) != 3 {
-				return &OtherError{Msg: fmt.Sprintf("if need 3 arguments. but got %d", len(args))}
-			}
 			cond := args[0]
 			t := args[1]
 			f := args[2]
-			if cond.Type() != BOOLEAN_OBJ {
-				return &TypeMisMatchError{Name: "if", Expected: BOOLEAN_OBJ, Got: cond}
-			}
 			if cond.(*Boolean).Value {
 				return t
 			} else {
@@ -38,4 +89,79 @@ var Buildins = map[string]Object{
 			}
 		},
 	},
-}
\ No newline at end of file
+	"type": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "value"},
+			},
+			Doc: "Return the type name of value.",
+		},
+		Fn: func(args ...Object) Object {
+			return &String{Value: string(args[0].Type())}
+		},
+	},
+	"help": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "fn", Types: callableTypes},
+			},
+			Doc: "Return the signature and the description of fn.",
+		},
+		Fn: func(args ...Object) Object {
+			if fn, ok := args[0].(*Buildin); ok && fn.Signature != nil {
+				return &String{Value: fn.Signature.String()+"\n"+fn.Signature.Doc}
+			}
+			if st, ok := args[0].(*StructType); ok {
+				return &String{Value: st.Signature.String()+"\n"+st.Signature.Doc}
+			}
+			return &String{Value: args[0].String()}
+		},
+	},
+}
+
+func isError(obj Object) bool {
+	return obj.Type() == ERROR_OBJ
+}
+
+// 整数も浮動小数点数にする。evaluatorの計算もこれを使うので、数の扱いはここで決める
+func ToFloat(val Object) float64 {
+	switch val := val.(type) {
+	case *Float:
+		return val.Value
+	case *Integer:
+		return float64(val.Value)
+	case *BigInteger:
+		f, _ := new(big.Float).SetInt(val.Value).Float64()
+		return f
+	}
+	return 0
+}
+
+// 浮動小数点数でない数を多倍長整数にする
+func ToBig(val Object) *big.Int {
+	switch val := val.(type) {
+	case *BigInteger:
+		return val.Value
+	case *Integer:
+		return big.NewInt(val.Value)
+	}
+	return new(big.Int)
+}
+
+// 型は宣言で調べてあるので、int64に収まらない整数だけをエラーにする
+func toInt64(name string, val Object) (int64, Object) {
+	if integer, ok := val.(*Integer); ok {
+		return integer.Value, nil
+	}
+	return 0, &OtherError{Msg: fmt.Sprintf("%s argument %s is too large", name, val.String())}
+}
+
+// 配列の中身のように、宣言では調べきれないものを調べる
+func checkTypes(name string, types []ObjectType, args ...Object) Object {
+	for _, arg := range args {
+		if !acceptsType(types, arg.Type()) {
+			return &TypeMisMatchError{Name: name, Expected: typesString(types), Got: arg}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,423 @@
+package object
+
+import (
+	"sort"
+)
+
+var arrayTypes = []ObjectType{ARRAY_OBJ}
+var integerTypes = []ObjectType{INTEGER_OBJ}
+
+// map(arr, f) のような、配列と関数を受け取る組み込み関数の引数
+var arrayAndFunctionParams = []Param{
+	{Name: "arr", Types: arrayTypes},
+	{Name: "f", Types: callableTypes},
+}
+
+var arrayBuildins = map[string]*Buildin{
+	"map": &Buildin{
+		Signature: &Signature{
+			Params: arrayAndFunctionParams,
+			Doc: "Return a new array of f applied to each element of arr.",
+		},
+		ContextFn: func(ctx *Context, args ...Object) Object {
+			elements := []Object{ }
+			for _, e := range args[0].(*Array).Elements {
+				result := ctx.Apply(args[1], []Object{e})
+				if isError(result) {
+					return result
+				}
+				elements = append(elements, result)
+			}
+			return &Array{Elements: elements}
+		},
+	},
+	"filter": &Buildin{
+		Signature: &Signature{
+			Params: arrayAndFunctionParams,
+			Doc: "Return a new array of the elements of arr for which f returns true.",
+		},
+		ContextFn: func(ctx *Context, args ...Object) Object {
+			elements := []Object{ }
+			for _, e := range args[0].(*Array).Elements {
+				ok, err := applyPredicate(ctx, "filter", args[1], e)
+				if err != nil {
+					return err
+				}
+				if ok {
+					elements = append(elements, e)
+				}
+			}
+			return &Array{Elements: elements}
+		},
+	},
+	"reduce": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "arr", Types: arrayTypes},
+				{Name: "f", Types: callableTypes},
+				{Name: "init", Optional: true},
+			},
+			Doc: "Fold arr from the left with f(acc, x). Without init, the first element is used.",
+		},
+		ContextFn: func(ctx *Context, args ...Object) Object {
+			elements := args[0].(*Array).Elements
+			var acc Object
+			if len(args) == 3 {
+				acc = args[2]
+			} else {
+				if len(elements) == 0 {
+					return &OtherError{Msg: "reduce of empty array with no initial value"}
+				}
+				acc = elements[0]
+				elements = elements[1:]
+			}
+			for _, e := range elements {
+				acc = ctx.Apply(args[1], []Object{acc, e})
+				if isError(acc) {
+					return acc
+				}
+			}
+			return acc
+		},
+	},
+	"each": &Buildin{
+		Signature: &Signature{
+			Params: arrayAndFunctionParams,
+			Doc: "Call f with each element of arr.",
+		},
+		ContextFn: func(ctx *Context, args ...Object) Object {
+			for _, e := range args[0].(*Array).Elements {
+				result := ctx.Apply(args[1], []Object{e})
+				if isError(result) {
+					return result
+				}
+			}
+			return &Null{ }
+		},
+	},
+	"any": &Buildin{
+		Signature: &Signature{
+			Params: arrayAndFunctionParams,
+			Doc: "Return whether f returns true for some element of arr.",
+		},
+		ContextFn: func(ctx *Context, args ...Object) Object {
+			for _, e := range args[0].(*Array).Elements {
+				ok, err := applyPredicate(ctx, "any", args[1], e)
+				if err != nil {
+					return err
+				}
+				if ok {
+					return &Boolean{Value: true}
+				}
+			}
+			return &Boolean{Value: false}
+		},
+	},
+	"all": &Buildin{
+		Signature: &Signature{
+			Params: arrayAndFunctionParams,
+			Doc: "Return whether f returns true for every element of arr.",
+		},
+		ContextFn: func(ctx *Context, args ...Object) Object {
+			for _, e := range args[0].(*Array).Elements {
+				ok, err := applyPredicate(ctx, "all", args[1], e)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return &Boolean{Value: false}
+				}
+			}
+			return &Boolean{Value: true}
+		},
+	},
+	"find": &Buildin{
+		Signature: &Signature{
+			Params: arrayAndFunctionParams,
+			Doc: "Return the first element of arr for which f returns true, or null.",
+		},
+		ContextFn: func(ctx *Context, args ...Object) Object {
+			for _, e := range args[0].(*Array).Elements {
+				ok, err := applyPredicate(ctx, "find", args[1], e)
+				if err != nil {
+					return err
+				}
+				if ok {
+					return e
+				}
+			}
+			return &Null{ }
+		},
+	},
+	"sort": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "arr", Types: arrayTypes},
+				{Name: "less", Types: callableTypes, Optional: true},
+			},
+			Doc: "Return a sorted copy of arr. less(a, b) returns true when a should come before b. Without less, any values can be sorted.",
+		},
+		ContextFn: func(ctx *Context, args ...Object) Object {
+			arr := args[0].(*Array)
+			elements := make([]Object, len(arr.Elements))
+			copy(elements, arr.Elements)
+			if len(args) == 2 {
+				// 比較関数は、1つ目を2つ目より前に置きたいときにtrueを返す
+				var err Object
+				sort.SliceStable(elements, func(i, j int) bool {
+					if err != nil {
+						return false
+					}
+					less, e := applyPredicate(ctx, "sort", args[1], elements[i], elements[j])
+					err = e
+					return less
+				})
+				if err != nil {
+					return err
+				}
+				return &Array{Elements: elements}
+			}
+			sort.SliceStable(elements, func(i, j int) bool {
+				return Compare(elements[i], elements[j]) < 0
+			})
+			return &Array{Elements: elements}
+		},
+	},
+	"reverse": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "value", Types: []ObjectType{ARRAY_OBJ, STRING_OBJ}},
+			},
+			Doc: "Return a reversed copy of an array or a string.",
+		},
+		Fn: func(args ...Object) Object {
+			switch arg := args[0].(type) {
+			case *Array:
+				elements := make([]Object, len(arg.Elements))
+				for i, e := range arg.Elements {
+					elements[len(elements)-1-i] = e
+				}
+				return &Array{Elements: elements}
+			default:
+				runes := []rune(arg.(*String).Value)
+				for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+					runes[i], runes[j] = runes[j], runes[i]
+				}
+				return &String{Value: string(runes)}
+			}
+		},
+	},
+	"zip": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "first", Types: arrayTypes},
+				{Name: "rest", Types: arrayTypes, Variadic: true},
+			},
+			Doc: "Return an array of arrays of the elements at the same index. Stops at the shortest array.",
+		},
+		Fn: func(args ...Object) Object {
+			// 一番短い配列に合わせる
+			length := len(args[0].(*Array).Elements)
+			for _, arg := range args[1:] {
+				if l := len(arg.(*Array).Elements); l < length {
+					length = l
+				}
+			}
+			elements := []Object{ }
+			for i := 0; i < length; i++ {
+				var tuple []Object
+				for _, arg := range args {
+					tuple = append(tuple, arg.(*Array).Elements[i])
+				}
+				elements = append(elements, &Array{Elements: tuple})
+			}
+			return &Array{Elements: elements}
+		},
+	},
+	"range": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "start", Types: integerTypes},
+				{Name: "stop", Types: integerTypes, Optional: true},
+				{Name: "step", Types: integerTypes, Optional: true},
+			},
+			Doc: "Return an array of integers. range(stop), range(start, stop) and range(start, stop, step). stop is excluded.",
+		},
+		Fn: func(args ...Object) Object {
+			var values []int64
+			for _, arg := range args {
+				value, err := toInt64("range", arg)
+				if err != nil {
+					return err
+				}
+				values = append(values, value)
+			}
+			// range(stop), range(start, stop), range(start, stop, step) で、stopは含まない
+			var start, stop, step int64 = 0, 0, 1
+			switch len(values) {
+			case 1:
+				stop = values[0]
+			case 2:
+				start = values[0]
+				stop = values[1]
+			case 3:
+				start = values[0]
+				stop = values[1]
+				step = values[2]
+			}
+			if step == 0 {
+				return &OtherError{Msg: "range step must not be zero"}
+			}
+			elements := []Object{ }
+			for i := start; step > 0 && i < stop || step < 0 && i > stop; i += step {
+				elements = append(elements, &Integer{Value: i})
+				// 次の値がstopに届くなら、足してあふれる前に止める。差はuint64ならあふれない
+				if step > 0 && uint64(stop)-uint64(i) <= uint64(step) || step < 0 && uint64(i)-uint64(stop) <= -uint64(step) {
+					break
+				}
+			}
+			return &Array{Elements: elements}
+		},
+	},
+	"flatten": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "arr", Types: arrayTypes},
+			},
+			Doc: "Flatten nested arrays into one array.",
+		},
+		Fn: func(args ...Object) Object {
+			elements, ok := flatten([]Object{ }, args[0].(*Array), map[*Array]bool{ })
+			if !ok {
+				return &OtherError{Msg: "flatten cannot flatten an array that contains itself"}
+			}
+			return &Array{Elements: elements}
+		},
+	},
+	"push": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "arr", Types: arrayTypes},
+				{Name: "value"},
+				{Name: "values", Variadic: true},
+			},
+			Doc: "Return a new array with values appended to arr. arr itself is not changed.",
+		},
+		Fn: func(args ...Object) Object {
+			// 元の配列は変えずに、新しい配列を返す
+			var elements []Object
+			elements = append(elements, args[0].(*Array).Elements...)
+			elements = append(elements, args[1:]...)
+			return &Array{Elements: elements}
+		},
+	},
+	"concat": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "arrs", Types: arrayTypes, Variadic: true},
+			},
+			Doc: "Concatenate arrays into a new array.",
+		},
+		Fn: func(args ...Object) Object {
+			elements := []Object{ }
+			for _, arg := range args {
+				elements = append(elements, arg.(*Array).Elements...)
+			}
+			return &Array{Elements: elements}
+		},
+	},
+	"slice": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "value", Types: []ObjectType{ARRAY_OBJ, STRING_OBJ}},
+				{Name: "start", Types: integerTypes},
+				{Name: "end", Types: integerTypes, Optional: true},
+			},
+			Doc: "Return the part of an array or a string from start to end. Negative indices count from the end.",
+		},
+		Fn: func(args ...Object) Object {
+			switch arg := args[0].(type) {
+			case *Array:
+				start, end := sliceRange(args[1:], len(arg.Elements))
+				elements := make([]Object, end-start)
+				copy(elements, arg.Elements[start:end])
+				return &Array{Elements: elements}
+			default:
+				runes := []rune(arg.(*String).Value)
+				start, end := sliceRange(args[1:], len(runes))
+				return &String{Value: string(runes[start:end])}
+			}
+		},
+	},
+}
+
+func isCallable(obj Object) bool {
+	return acceptsType(callableTypes, obj.Type())
+}
+
+// 真偽値を返すはずの関数を呼ぶ
+func applyPredicate(ctx *Context, name string, fn Object, args ...Object) (bool, Object) {
+	result := ctx.Apply(fn, args)
+	if isError(result) {
+		return false, result
+	}
+	b, ok := result.(*Boolean)
+	if !ok {
+		return false, &TypeMisMatchError{Name: name, Expected: BOOLEAN_OBJ, Got: result}
+	}
+	return b.Value, nil
+}
+
+// 自分自身を含む配列は平らにできないので、たどっている途中の配列に戻ったらfalseを返す
+func flatten(result []Object, arr *Array, visiting map[*Array]bool) ([]Object, bool) {
+	if visiting[arr] {
+		return nil, false
+	}
+	visiting[arr] = true
+	defer delete(visiting, arr)
+	for _, e := range arr.Elements {
+		if inner, ok := e.(*Array); ok {
+			var flat bool
+			result, flat = flatten(result, inner, visiting)
+			if !flat {
+				return nil, false
+			}
+		} else {
+			result = append(result, e)
+		}
+	}
+	return result, true
+}
+
+// 負の位置は後ろから数え、範囲外は端に丸める
+func sliceRange(args []Object, length int) (int, int) {
+	index := func(obj Object) int {
+		// int64に収まらない位置は、どちらかの端に丸める
+		if huge, ok := obj.(*BigInteger); ok {
+			if huge.Value.Sign() < 0 {
+				return 0
+			}
+			return length
+		}
+		i := obj.(*Integer).Value
+		if i < 0 {
+			i += int64(length)
+		}
+		if i < 0 {
+			return 0
+		}
+		if i > int64(length) {
+			return length
+		}
+		return int(i)
+	}
+	start := index(args[0])
+	end := length
+	if len(args) == 2 {
+		end = index(args[1])
+	}
+	if end < start {
+		end = start
+	}
+	return start, end
+}
@@ -0,0 +1,104 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+)
+
+var evalBuildins = map[string]*Buildin{
+	"apply": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "f", Types: callableTypes},
+				{Name: "args", Types: arrayTypes},
+			},
+			Doc: "Call f with the elements of args as arguments.",
+		},
+		ContextFn: func(ctx *Context, args ...Object) Object {
+			return ctx.Apply(args[0], args[1].(*Array).Elements)
+		},
+	},
+	"try": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "f", Types: callableTypes},
+				{Name: "handler", Types: callableTypes, Optional: true},
+			},
+			Doc: "Call f. If it fails, call handler with the error message, or return null without handler.",
+		},
+		ContextFn: func(ctx *Context, args ...Object) Object {
+			// エラーになったら、2つ目の関数にエラーメッセージを渡して呼ぶ
+			result := ctx.Apply(args[0], []Object{ })
+			if !isError(result) {
+				return result
+			}
+			if len(args) == 1 {
+				return &Null{ }
+			}
+			return ctx.Apply(args[1], []Object{&String{Value: result.String()}})
+		},
+	},
+	"error": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "msg", Types: stringTypes},
+			},
+			Doc: "Return an error with msg.",
+		},
+		Fn: func(args ...Object) Object {
+			return &OtherError{Msg: args[0].(*String).Value}
+		},
+	},
+	"assert": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "cond", Types: []ObjectType{BOOLEAN_OBJ}},
+				{Name: "msg", Types: stringTypes, Optional: true},
+			},
+			Doc: "Return an error with the position of the call if cond is false.",
+		},
+		ContextFn: func(ctx *Context, args ...Object) Object {
+			if args[0].(*Boolean).Value {
+				return &Null{ }
+			}
+			msg := fmt.Sprintf("assertion failed at %s", ctx.Position)
+			if len(args) == 2 {
+				msg += ": " + args[1].(*String).Value
+			}
+			return &OtherError{Msg: msg}
+		},
+	},
+	"eval": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "src", Types: stringTypes},
+			},
+			Doc: "Evaluate src in the environment of the caller.",
+		},
+		ContextFn: func(ctx *Context, args ...Object) Object {
+			program, errors := ctx.Parse(args[0].(*String).Value)
+			if len(errors) != 0 {
+				return &OtherError{Msg: "eval could not parse: " + strings.Join(errors, ", ")}
+			}
+			// 呼び出したところの環境で評価する
+			result := ctx.Eval(program, ctx.Env)
+			if result.Type() == SHOULD_NOT_VIEWABLE_OBJ {
+				return &Null{ }
+			}
+			return result
+		},
+	},
+	"gets": &Buildin{
+		Signature: &Signature{
+			Doc: "Read a line from the input without the newline. Return null at the end of the input.",
+		},
+		ContextFn: func(ctx *Context, args ...Object) Object {
+			// 1行読んで、改行を取り除いて返す。もう読めなければnull
+			line, err := ctx.In.ReadString('\n')
+			if err != nil && line == "" {
+				return &Null{ }
+			}
+			return &String{Value: strings.TrimRight(line, "\r\n")}
+		},
+	},
+}
@@ -0,0 +1,39 @@
+package object
+
+var hashBuildins = map[string]*Buildin{
+	"create": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "proto", Types: []ObjectType{HASH_OBJ}},
+				{Name: "fields", Types: []ObjectType{HASH_OBJ}, Optional: true},
+			},
+			Doc: "Create a hash that delegates missing members to proto, with a copy of fields.",
+		},
+		Fn: func(args ...Object) Object {
+			hash := NewHash()
+			hash.Proto = args[0].(*Hash)
+			if len(args) == 2 {
+				fields := args[1].(*Hash)
+				for _, key := range fields.Order {
+					pair := fields.Pairs[key]
+					hash.Set(pair.Key, pair.Value)
+				}
+			}
+			return hash
+		},
+	},
+	"proto": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "hash", Types: []ObjectType{HASH_OBJ}},
+			},
+			Doc: "Return the prototype of hash, or null if it has none.",
+		},
+		Fn: func(args ...Object) Object {
+			if proto := args[0].(*Hash).Proto; proto != nil {
+				return proto
+			}
+			return &Null{ }
+		},
+	},
+}
@@ -0,0 +1,354 @@
+package object
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"yokan/utility"
+)
+
+// min([1, 2]) のように配列でも渡せる
+var numberOrArrayTypes = []ObjectType{INTEGER_OBJ, FLOAT_OBJ, ARRAY_OBJ}
+
+var numberBuildins = map[string]*Buildin{
+	"int": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "value", Types: []ObjectType{INTEGER_OBJ, FLOAT_OBJ, STRING_OBJ, BOOLEAN_OBJ}},
+			},
+			Doc: "Convert value to an integer. Floats are truncated toward zero, strings may have 0x, 0o or 0b prefixes.",
+		},
+		Fn: func(args ...Object) Object {
+			switch arg := args[0].(type) {
+			case *Float:
+				// 0に向かって切り捨てる
+				if math.IsNaN(arg.Value) || math.IsInf(arg.Value, 0) {
+					return &OtherError{Msg: fmt.Sprintf("int could not convert %s to integer", arg.String())}
+				}
+				value, _ := big.NewFloat(arg.Value).Int(nil)
+				return NewInteger(value)
+			case *String:
+				return parseInteger(arg.Value)
+			case *Boolean:
+				if arg.Value {
+					return &Integer{Value: 1}
+				}
+				return &Integer{Value: 0}
+			default:
+				return arg
+			}
+		},
+	},
+	"float": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "value", Types: []ObjectType{FLOAT_OBJ, INTEGER_OBJ, STRING_OBJ}},
+			},
+			Doc: "Convert value to a float.",
+		},
+		Fn: func(args ...Object) Object {
+			switch arg := args[0].(type) {
+			case *String:
+				value, err := strconv.ParseFloat(strings.TrimSpace(arg.Value), 64)
+				if err != nil {
+					return &OtherError{Msg: fmt.Sprintf("float could not parse %s as float", arg.String())}
+				}
+				return &Float{Value: value}
+			case *Float:
+				return arg
+			default:
+				return &Float{Value: ToFloat(arg)}
+			}
+		},
+	},
+	"str": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "value"},
+			},
+			Doc: "Convert value to a string.",
+		},
+		Fn: func(args ...Object) Object {
+			if str, ok := args[0].(*String); ok {
+				return str
+			}
+			return &String{Value: args[0].String()}
+		},
+	},
+	"bool": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "value", Types: []ObjectType{BOOLEAN_OBJ, INTEGER_OBJ, FLOAT_OBJ, STRING_OBJ, NULL_OBJ}},
+			},
+			Doc: "Convert value to a boolean. Only \"true\" and \"false\" are accepted as strings.",
+		},
+		Fn: func(args ...Object) Object {
+			switch arg := args[0].(type) {
+			case *Integer:
+				return &Boolean{Value: arg.Value != 0}
+			case *BigInteger:
+				return &Boolean{Value: arg.Value.Sign() != 0}
+			case *Float:
+				return &Boolean{Value: arg.Value != 0}
+			case *String:
+				switch strings.TrimSpace(arg.Value) {
+				case "true":
+					return &Boolean{Value: true}
+				case "false":
+					return &Boolean{Value: false}
+				}
+				return &OtherError{Msg: fmt.Sprintf("bool could not parse %s as boolean", arg.String())}
+			case *Null:
+				return &Boolean{Value: false}
+			default:
+				return arg
+			}
+		},
+	},
+	"abs": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "x", Types: numberTypes},
+			},
+			Doc: "Return the absolute value of x.",
+		},
+		Fn: func(args ...Object) Object {
+			switch arg := args[0].(type) {
+			case *Float:
+				return &Float{Value: math.Abs(arg.Value)}
+			case *Integer:
+				if arg.Value >= 0 {
+					return arg
+				}
+			}
+			return NewInteger(new(big.Int).Abs(ToBig(args[0])))
+		},
+	},
+	"min": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "values", Types: numberOrArrayTypes, Variadic: true},
+			},
+			Doc: "Return the smallest of values. An array of numbers can be passed instead.",
+		},
+		Fn: func(args ...Object) Object {
+			return extremeNumber("min", args, -1)
+		},
+	},
+	"max": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "values", Types: numberOrArrayTypes, Variadic: true},
+			},
+			Doc: "Return the largest of values. An array of numbers can be passed instead.",
+		},
+		Fn: func(args ...Object) Object {
+			return extremeNumber("max", args, 1)
+		},
+	},
+	"pow": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "base", Types: numberTypes},
+				{Name: "exp", Types: numberTypes},
+			},
+			Doc: "Return base raised to exp. Integers raised to non-negative integers stay exact.",
+		},
+		Fn: func(args ...Object) Object {
+			base := args[0]
+			exp := args[1]
+			// 整数の0以上の整数乗だけは、誤差が出ないように整数で計算する
+			if base.Type() == INTEGER_OBJ && exp.Type() == INTEGER_OBJ && ToBig(exp).Sign() >= 0 {
+				if !ToBig(exp).IsInt64() || ToBig(exp).Int64() > maxPowExponent {
+					return &OtherError{Msg: fmt.Sprintf("pow exponent is too large. got %s", exp.String())}
+				}
+				return NewInteger(new(big.Int).Exp(ToBig(base), ToBig(exp), nil))
+			}
+			return &Float{Value: math.Pow(ToFloat(base), ToFloat(exp))}
+		},
+	},
+	"sqrt": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "x", Types: numberTypes},
+			},
+			Doc: "Return the square root of x as a float.",
+		},
+		Fn: func(args ...Object) Object {
+			if ToFloat(args[0]) < 0 {
+				return &OtherError{Msg: fmt.Sprintf("sqrt of negative number %s", args[0].String())}
+			}
+			return &Float{Value: math.Sqrt(ToFloat(args[0]))}
+		},
+	},
+	"floor": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "x", Types: numberTypes},
+			},
+			Doc: "Round x down to an integer.",
+		},
+		Fn: func(args ...Object) Object {
+			return roundNumber("floor", args[0], math.Floor)
+		},
+	},
+	"ceil": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "x", Types: numberTypes},
+			},
+			Doc: "Round x up to an integer.",
+		},
+		Fn: func(args ...Object) Object {
+			return roundNumber("ceil", args[0], math.Ceil)
+		},
+	},
+	"gcd": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "a", Types: []ObjectType{INTEGER_OBJ}},
+				{Name: "b", Types: []ObjectType{INTEGER_OBJ}},
+			},
+			Doc: "Return the greatest common divisor of a and b.",
+		},
+		Fn: func(args ...Object) Object {
+			return NewInteger(gcd(ToBig(args[0]), ToBig(args[1])))
+		},
+	},
+	"lcm": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "a", Types: []ObjectType{INTEGER_OBJ}},
+				{Name: "b", Types: []ObjectType{INTEGER_OBJ}},
+			},
+			Doc: "Return the least common multiple of a and b.",
+		},
+		Fn: func(args ...Object) Object {
+			a := ToBig(args[0])
+			b := ToBig(args[1])
+			if a.Sign() == 0 || b.Sign() == 0 {
+				return &Integer{Value: 0}
+			}
+			// |a*b| / gcd(a, b)
+			product := new(big.Int).Abs(new(big.Int).Mul(a, b))
+			return NewInteger(product.Quo(product, gcd(a, b)))
+		},
+	},
+	"clamp": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "x", Types: numberTypes},
+				{Name: "lo", Types: numberTypes},
+				{Name: "hi", Types: numberTypes},
+			},
+			Doc: "Limit x to the range from lo to hi.",
+		},
+		Fn: func(args ...Object) Object {
+			x := args[0]
+			lo := args[1]
+			hi := args[2]
+			if compareNumbers(lo, hi) > 0 {
+				return &OtherError{Msg: fmt.Sprintf("clamp lower bound %s is greater than upper bound %s", lo.String(), hi.String())}
+			}
+			if compareNumbers(x, lo) < 0 {
+				return lo
+			}
+			if compareNumbers(x, hi) > 0 {
+				return hi
+			}
+			return x
+		},
+	},
+}
+
+const maxPowExponent = 1 << 20
+
+// 0x, 0o, 0bの接頭辞があればその基数で読む
+// strconvの基数0とは違い、0から始まるだけの数は8進数ではなく10進数として扱う
+func parseInteger(str string) Object {
+	digits := strings.TrimSpace(str)
+	sign := ""
+	if strings.HasPrefix(digits, "+") || strings.HasPrefix(digits, "-") {
+		sign = digits[:1]
+		digits = digits[1:]
+	}
+	base := 10
+	if len(digits) > 2 && digits[0] == '0' {
+		switch digits[1] {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+		if base != 10 {
+			digits = digits[2:]
+		}
+	}
+	// int64に収まらなくても読めるように、math/bigで読む
+	// SetStringは基数を指定すると接頭辞も_も受け付けないので、数字以外を弾く手間がいらない
+	value, ok := new(big.Int).SetString(sign+digits, base)
+	if !ok {
+		return &OtherError{Msg: fmt.Sprintf("int could not parse %s as integer", utility.Quote(str))}
+	}
+	return NewInteger(value)
+}
+
+// 数どうしを比べて、a<bなら負、a==bなら0、a>bなら正を返す
+func compareNumbers(a Object, b Object) int {
+	if a.Type() == FLOAT_OBJ || b.Type() == FLOAT_OBJ {
+		x := ToFloat(a)
+		y := ToFloat(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return ToBig(a).Cmp(ToBig(b))
+}
+
+// min(1, 2, 3) のように並べても、min([1, 2, 3]) のように配列で渡してもよい
+// sign が負なら最小値、正なら最大値を返す
+func extremeNumber(name string, args []Object, sign int) Object {
+	if len(args) == 1 {
+		if arr, ok := args[0].(*Array); ok {
+			args = arr.Elements
+		}
+	}
+	if len(args) < 1 {
+		return &OtherError{Msg: fmt.Sprintf("%s need at least 1 arguments. but got %d", name, len(args))}
+	}
+	if err := checkTypes(name, numberTypes, args...); err != nil {
+		return err
+	}
+	result := args[0]
+	for _, arg := range args[1:] {
+		if compareNumbers(arg, result)*sign > 0 {
+			result = arg
+		}
+	}
+	return result
+}
+
+func roundNumber(name string, arg Object, round func(float64) float64) Object {
+	f, ok := arg.(*Float)
+	if !ok {
+		return arg
+	}
+	if math.IsNaN(f.Value) || math.IsInf(f.Value, 0) {
+		return &OtherError{Msg: fmt.Sprintf("%s could not convert %s to integer", name, f.String())}
+	}
+	value, _ := big.NewFloat(round(f.Value)).Int(nil)
+	return NewInteger(value)
+}
+
+func gcd(a *big.Int, b *big.Int) *big.Int {
+	// big.Int.GCDは負の数を受け付けないので、絶対値にしてから渡す
+	return new(big.Int).GCD(nil, nil, new(big.Int).Abs(a), new(big.Int).Abs(b))
+}
@@ -0,0 +1,302 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode/utf8"
+)
+
+var stringTypes = []ObjectType{STRING_OBJ}
+
+const maxInt = int(^uint(0) >> 1)
+
+var stringBuildins = map[string]*Buildin{
+	"len": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "value", Types: []ObjectType{STRING_OBJ, ARRAY_OBJ}},
+			},
+			Doc: "Return the number of characters of a string, or the number of elements of an array.",
+		},
+		Fn: func(args ...Object) Object {
+			switch arg := args[0].(type) {
+			case *String:
+				return &Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+			default:
+				return &Integer{Value: int64(len(arg.(*Array).Elements))}
+			}
+		},
+	},
+	"split": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "str", Types: stringTypes},
+				{Name: "sep", Types: stringTypes},
+			},
+			Doc: "Split str by sep into an array of strings.",
+		},
+		Fn: func(args ...Object) Object {
+			var elements []Object
+			for _, s := range strings.Split(args[0].(*String).Value, args[1].(*String).Value) {
+				elements = append(elements, &String{Value: s})
+			}
+			return &Array{Elements: elements}
+		},
+	},
+	"join": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "strs", Types: []ObjectType{ARRAY_OBJ}},
+				{Name: "sep", Types: stringTypes},
+			},
+			Doc: "Join an array of strings with sep.",
+		},
+		Fn: func(args ...Object) Object {
+			arr := args[0].(*Array)
+			if err := checkTypes("join", stringTypes, arr.Elements...); err != nil {
+				return err
+			}
+			var strs []string
+			for _, e := range arr.Elements {
+				strs = append(strs, e.(*String).Value)
+			}
+			return &String{Value: strings.Join(strs, args[1].(*String).Value)}
+		},
+	},
+	"trim": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "str", Types: stringTypes},
+			},
+			Doc: "Remove leading and trailing white spaces.",
+		},
+		Fn: func(args ...Object) Object {
+			return &String{Value: strings.TrimSpace(args[0].(*String).Value)}
+		},
+	},
+	"upper": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "str", Types: stringTypes},
+			},
+			Doc: "Convert str to upper case.",
+		},
+		Fn: func(args ...Object) Object {
+			return &String{Value: strings.ToUpper(args[0].(*String).Value)}
+		},
+	},
+	"lower": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "str", Types: stringTypes},
+			},
+			Doc: "Convert str to lower case.",
+		},
+		Fn: func(args ...Object) Object {
+			return &String{Value: strings.ToLower(args[0].(*String).Value)}
+		},
+	},
+	"replace": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "str", Types: stringTypes},
+				{Name: "old", Types: stringTypes},
+				{Name: "new", Types: stringTypes},
+			},
+			Doc: "Replace all old in str with new.",
+		},
+		Fn: func(args ...Object) Object {
+			str := args[0].(*String).Value
+			old := args[1].(*String).Value
+			new := args[2].(*String).Value
+			return &String{Value: strings.Replace(str, old, new, -1)}
+		},
+	},
+	"contains": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "str", Types: stringTypes},
+				{Name: "sub", Types: stringTypes},
+			},
+			Doc: "Return whether str contains sub.",
+		},
+		Fn: func(args ...Object) Object {
+			return &Boolean{Value: strings.Contains(args[0].(*String).Value, args[1].(*String).Value)}
+		},
+	},
+	"starts_with": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "str", Types: stringTypes},
+				{Name: "prefix", Types: stringTypes},
+			},
+			Doc: "Return whether str starts with prefix.",
+		},
+		Fn: func(args ...Object) Object {
+			return &Boolean{Value: strings.HasPrefix(args[0].(*String).Value, args[1].(*String).Value)}
+		},
+	},
+	"ends_with": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "str", Types: stringTypes},
+				{Name: "suffix", Types: stringTypes},
+			},
+			Doc: "Return whether str ends with suffix.",
+		},
+		Fn: func(args ...Object) Object {
+			return &Boolean{Value: strings.HasSuffix(args[0].(*String).Value, args[1].(*String).Value)}
+		},
+	},
+	"index_of": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "str", Types: stringTypes},
+				{Name: "sub", Types: stringTypes},
+			},
+			Doc: "Return the character index of the first sub in str, or -1.",
+		},
+		Fn: func(args ...Object) Object {
+			str := args[0].(*String).Value
+			idx := strings.Index(str, args[1].(*String).Value)
+			if idx < 0 {
+				return &Integer{Value: -1}
+			}
+			// lenやcharsと揃えるため、バイト数ではなく文字数で返す
+			return &Integer{Value: int64(utf8.RuneCountInString(str[:idx]))}
+		},
+	},
+	"chars": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "str", Types: stringTypes},
+			},
+			Doc: "Split str into an array of characters.",
+		},
+		Fn: func(args ...Object) Object {
+			elements := []Object{ }
+			for _, r := range args[0].(*String).Value {
+				elements = append(elements, &String{Value: string(r)})
+			}
+			return &Array{Elements: elements}
+		},
+	},
+	"repeat": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "str", Types: stringTypes},
+				{Name: "count", Types: []ObjectType{INTEGER_OBJ}},
+			},
+			Doc: "Repeat str count times.",
+		},
+		Fn: func(args ...Object) Object {
+			count, err := toInt64("repeat", args[1])
+			if err != nil {
+				return err
+			}
+			if count < 0 {
+				return &OtherError{Msg: fmt.Sprintf("repeat count must not be negative. but got %d", count)}
+			}
+			str := args[0].(*String).Value
+			// strings.Repeat は長さが int に収まらないと panic する
+			if len(str) != 0 && count > int64(maxInt/len(str)) {
+				return &OtherError{Msg: fmt.Sprintf("repeat result is too long. %d * %d bytes", count, len(str))}
+			}
+			return &String{Value: strings.Repeat(str, int(count))}
+		},
+	},
+	"format": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "format", Types: stringTypes},
+				{Name: "values", Variadic: true},
+			},
+			Doc: "Format values like printf. Verbs: %d %x %X %o %b %f %e %g %s %q %t %v %%",
+		},
+		Fn: func(args ...Object) Object {
+			return formatString(args[0].(*String).Value, args[1:])
+		},
+	},
+}
+
+// printfと同じような書式で文字列を作る
+// 対応している動詞は %d %x %X %o %b %f %e %g %s %q %t %v %% で、フラグや幅も書ける
+func formatString(format string, args []Object) Object {
+	var out bytes.Buffer
+	argIdx := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			out.WriteByte(format[i])
+			continue
+		}
+		start := i
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0123456789.", format[i]) >= 0 {
+			i++
+		}
+		if i >= len(format) {
+			return &OtherError{Msg: fmt.Sprintf("format %q ends with an incomplete verb", format)}
+		}
+		verb := format[i]
+		spec := format[start:i+1]
+		if verb == '%' {
+			out.WriteByte('%')
+			continue
+		}
+		if argIdx >= len(args) {
+			return &OtherError{Msg: fmt.Sprintf("format %q needs more arguments. but got %d", format, len(args))}
+		}
+		arg := args[argIdx]
+		argIdx++
+		switch verb {
+		case 'd', 'x', 'X', 'o', 'b':
+			switch integer := arg.(type) {
+			case *Integer:
+				out.WriteString(fmt.Sprintf(spec, integer.Value))
+			case *BigInteger:
+				out.WriteString(fmt.Sprintf(spec, integer.Value))
+			default:
+				return &TypeMisMatchError{Name: "format", Expected: INTEGER_OBJ, Got: arg}
+			}
+		case 'f', 'F', 'e', 'E', 'g', 'G':
+			switch number := arg.(type) {
+			case *Float:
+				out.WriteString(fmt.Sprintf(spec, number.Value))
+			case *Integer:
+				out.WriteString(fmt.Sprintf(spec, float64(number.Value)))
+			case *BigInteger:
+				value, _ := new(big.Float).SetInt(number.Value).Float64()
+				out.WriteString(fmt.Sprintf(spec, value))
+			default:
+				return &TypeMisMatchError{Name: "format", Expected: FLOAT_OBJ+", "+INTEGER_OBJ, Got: arg}
+			}
+		case 's', 'q':
+			str, ok := arg.(*String)
+			if !ok {
+				return &TypeMisMatchError{Name: "format", Expected: STRING_OBJ, Got: arg}
+			}
+			out.WriteString(fmt.Sprintf(spec, str.Value))
+		case 't':
+			boolean, ok := arg.(*Boolean)
+			if !ok {
+				return &TypeMisMatchError{Name: "format", Expected: BOOLEAN_OBJ, Got: arg}
+			}
+			out.WriteString(fmt.Sprintf(spec, boolean.Value))
+		case 'v':
+			// putsと同じく、文字列だけはクォートせずにそのまま出す
+			if str, ok := arg.(*String); ok {
+				out.WriteString(fmt.Sprintf(spec, str.Value))
+			} else {
+				out.WriteString(fmt.Sprintf(spec, arg.String()))
+			}
+		default:
+			return &OtherError{Msg: fmt.Sprintf("format verb '%%%c' is not supported", verb)}
+		}
+	}
+	if argIdx != len(args) {
+		return &OtherError{Msg: fmt.Sprintf("format %q uses %d arguments. but got %d", format, argIdx, len(args))}
+	}
+	return &String{Value: out.String()}
+}
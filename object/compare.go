@@ -0,0 +1,254 @@
+package object
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// 値が等しいかを調べる
+// 配列とハッシュは中身を比べ、関数と組み込み関数は同じものかどうかで比べる
+func Equals(a Object, b Object) bool {
+	return newComparer().equals(a, b)
+}
+
+// すべての値を並べられる順序で比べて、a<bなら負、a==bなら0、a>bなら正を返す
+// 型が違うときは null < 真偽値 < 数 < 文字列 < 配列 < ハッシュ < 関数 < 組み込み関数 の順にする
+func Compare(a Object, b Object) int {
+	return newComparer().compare(a, b)
+}
+
+// 自分自身を含む配列やハッシュでも止まるよう、比べている途中の組を覚えておく
+type comparer struct {
+	visiting map[[2]Object]bool
+}
+
+func newComparer() *comparer {
+	return &comparer{visiting: map[[2]Object]bool{ }}
+}
+
+// 比べている途中の組にもう一度来たら、そこまでは同じだったとみなす
+func (c *comparer) enter(a Object, b Object) bool {
+	pair := [2]Object{a, b}
+	if c.visiting[pair] {
+		return false
+	}
+	c.visiting[pair] = true
+	return true
+}
+
+func (c *comparer) leave(a Object, b Object) {
+	delete(c.visiting, [2]Object{a, b})
+}
+
+func (c *comparer) equals(a Object, b Object) bool {
+	if isNumber(a) && isNumber(b) {
+		if a.Type() == FLOAT_OBJ || b.Type() == FLOAT_OBJ {
+			// 1 == 1.0 のように、整数と浮動小数点数も値が同じなら等しい
+			return ToFloat(a) == ToFloat(b)
+		}
+		return ToBig(a).Cmp(ToBig(b)) == 0
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+	switch a := a.(type) {
+	case *String:
+		return a.Value == b.(*String).Value
+	case *Boolean:
+		return a.Value == b.(*Boolean).Value
+	case *Null:
+		return true
+	case *Array:
+		b := b.(*Array)
+		if a == b || len(a.Elements) != len(b.Elements) {
+			return a == b
+		}
+		if !c.enter(a, b) {
+			return true
+		}
+		defer c.leave(a, b)
+		for i, e := range a.Elements {
+			if !c.equals(e, b.Elements[i]) {
+				return false
+			}
+		}
+		return true
+	case *Struct:
+		b := b.(*Struct)
+		if a == b || a.Def != b.Def {
+			return a == b
+		}
+		if !c.enter(a, b) {
+			return true
+		}
+		defer c.leave(a, b)
+		for i, v := range a.Values {
+			if !c.equals(v, b.Values[i]) {
+				return false
+			}
+		}
+		return true
+	case *Hash:
+		b := b.(*Hash)
+		if a == b || len(a.Pairs) != len(b.Pairs) {
+			return a == b
+		}
+		if !c.enter(a, b) {
+			return true
+		}
+		defer c.leave(a, b)
+		for key, pair := range a.Pairs {
+			other, ok := b.Pairs[key]
+			if !ok || !c.equals(pair.Value, other.Value) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+func (c *comparer) compare(a Object, b Object) int {
+	if rankA, rankB := typeRank(a), typeRank(b); rankA != rankB {
+		return rankA - rankB
+	}
+	switch a := a.(type) {
+	case *Null:
+		return 0
+	case *Boolean:
+		return compareBool(a.Value, b.(*Boolean).Value)
+	case *String:
+		return strings.Compare(a.Value, b.(*String).Value)
+	case *Array:
+		b := b.(*Array)
+		if !c.enter(a, b) {
+			return 0
+		}
+		defer c.leave(a, b)
+		// 辞書順
+		for i := 0; i < len(a.Elements) && i < len(b.Elements); i++ {
+			if cmp := c.compare(a.Elements[i], b.Elements[i]); cmp != 0 {
+				return cmp
+			}
+		}
+		return len(a.Elements) - len(b.Elements)
+	case *Hash:
+		b := b.(*Hash)
+		if !c.enter(a, b) {
+			return 0
+		}
+		defer c.leave(a, b)
+		// キーの順に並べた (キー, 値) の列を辞書順で比べる
+		pairsA := c.sortedPairs(a)
+		pairsB := c.sortedPairs(b)
+		for i := 0; i < len(pairsA) && i < len(pairsB); i++ {
+			if cmp := c.compare(pairsA[i].Key, pairsB[i].Key); cmp != 0 {
+				return cmp
+			}
+			if cmp := c.compare(pairsA[i].Value, pairsB[i].Value); cmp != 0 {
+				return cmp
+			}
+		}
+		return len(pairsA) - len(pairsB)
+	case *Struct:
+		b := b.(*Struct)
+		if cmp := strings.Compare(a.Def.Name, b.Def.Name); cmp != 0 || a.Def != b.Def {
+			if cmp == 0 {
+				// 同じ名前の別の型は、フィールドの並びで比べる
+				return strings.Compare(a.Def.String(), b.Def.String())
+			}
+			return cmp
+		}
+		if !c.enter(a, b) {
+			return 0
+		}
+		defer c.leave(a, b)
+		for i, v := range a.Values {
+			if cmp := c.compare(v, b.Values[i]); cmp != 0 {
+				return cmp
+			}
+		}
+		return 0
+	}
+	if isNumber(a) {
+		return compareNumbersWithNaN(a, b)
+	}
+	// 関数どうしは大小がないので、表示したときの文字列で並べる
+	// Equalsと同じく同じものだけを等しくするため、同じ文字列になる別の関数は置かれた場所の順にする
+	if a == b {
+		return 0
+	}
+	if cmp := strings.Compare(a.String(), b.String()); cmp != 0 {
+		return cmp
+	}
+	if reflect.ValueOf(a).Pointer() < reflect.ValueOf(b).Pointer() {
+		return -1
+	}
+	return 1
+}
+
+func (c *comparer) sortedPairs(h *Hash) []HashPair {
+	var pairs []HashPair
+	for _, key := range h.Order {
+		pairs = append(pairs, h.Pairs[key])
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return c.compare(pairs[i].Key, pairs[j].Key) < 0
+	})
+	return pairs
+}
+
+func typeRank(obj Object) int {
+	switch obj.Type() {
+	case NULL_OBJ:
+		return 0
+	case BOOLEAN_OBJ:
+		return 1
+	case INTEGER_OBJ, FLOAT_OBJ:
+		return 2
+	case STRING_OBJ:
+		return 3
+	case ARRAY_OBJ:
+		return 4
+	case HASH_OBJ:
+		return 5
+	case STRUCT_OBJ:
+		return 6
+	case FUNCTION_OBJ:
+		return 7
+	case BUILDIN_OBJ:
+		return 8
+	}
+	return 9
+}
+
+func isNumber(obj Object) bool {
+	return obj.Type() == INTEGER_OBJ || obj.Type() == FLOAT_OBJ
+}
+
+func compareBool(a bool, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	}
+	return -1
+}
+
+// 並べるときはnanを一番大きい数として扱う
+func compareNumbersWithNaN(a Object, b Object) int {
+	nanA := a.Type() == FLOAT_OBJ && math.IsNaN(a.(*Float).Value)
+	nanB := b.Type() == FLOAT_OBJ && math.IsNaN(b.(*Float).Value)
+	switch {
+	case nanA && nanB:
+		return 0
+	case nanA:
+		return 1
+	case nanB:
+		return -1
+	}
+	return compareNumbers(a, b)
+}
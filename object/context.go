@@ -0,0 +1,29 @@
+package object
+
+import (
+	"bufio"
+	"io"
+	"yokan/ast"
+	"yokan/token"
+)
+
+// 組み込み関数が呼ばれたときの状況
+// これを受け取る組み込み関数は、ユーザーの関数を呼んだり、呼び出し元の環境で評価したりできる
+type Context struct {
+	// 呼び出したところの環境
+	Env *Environment
+	// 呼び出したところの位置
+	Position token.Position
+	Out io.Writer
+	In *bufio.Reader
+	// 関数を呼ぶ(objectはevaluatorをimportできないので、evaluatorに入れてもらう)
+	Apply func(fn Object, args []Object) Object
+	// 構文木を評価する
+	Eval func(node ast.Node, env *Environment) Object
+	// ソースを構文木にする。読めなければ構文エラーを返す(objectはparserをimportしないので、これもevaluatorに入れてもらう)
+	Parse func(src string) (*ast.Program, []string)
+	// importするファイルを読んで構文木にする。読めなければerrを、構文エラーがあればそれを返す
+	Load func(path string) (program *ast.Program, errors []string, err error)
+}
+
+type ContextBuildinFunction func(ctx *Context, args ...Object) Object
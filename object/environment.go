@@ -1,7 +1,35 @@
 package object
 
+import (
+	"bufio"
+	"io"
+	"math/rand"
+	"os"
+	"time"
+)
+
+// インタプリタひとつにつきひとつ作る
+// 代入でBuildinsそのものを書き換えないよう、組み込みはコピーしておく
 func NewEnvironment() *Environment {
-	return &Environment{store: Buildins, parent: nil}
+	return newEnvironment(rand.New(rand.NewSource(time.Now().UnixNano())))
+}
+
+// 乱数の状態を渡せば、seedの効き目を共有できる
+func newEnvironment(random *rand.Rand) *Environment {
+	store := make(map[string]Object)
+	for name, obj := range Buildins {
+		store[name] = obj
+	}
+	registerBuildins(store, newRandomBuildins(random))
+	return &Environment{
+		store: store,
+		parent: nil,
+		out: os.Stdout,
+		in: bufio.NewReader(os.Stdin),
+		contracts: true,
+		random: random,
+		modules: newModules(),
+	}
 }
 
 func NewInferitEnvironment(parent *Environment) *Environment {
@@ -15,6 +43,15 @@ func NewInferitEnvironment(parent *Environment) *Environment {
 type Environment struct {
 	store map[string]Object
 	parent *Environment
+	// 入出力は一番外側の環境だけが持つ
+	out io.Writer
+	in *bufio.Reader
+	// falseなら関数の requires と ensures を調べない
+	contracts bool
+	random *rand.Rand
+	// 実行しているファイルの絶対パス。REPLなら空
+	file string
+	modules *modules
 }
 
 func (e *Environment) Get(name string) (Object, bool) {
@@ -28,3 +65,34 @@ func (e *Environment) Get(name string) (Object, bool) {
 func (e *Environment) Set(name string, val Object) {
 	e.store[name] = val
 }
+
+func (e *Environment) root() *Environment {
+	if e.parent == nil {
+		return e
+	}
+	return e.parent.root()
+}
+
+func (e *Environment) Output() io.Writer {
+	return e.root().out
+}
+
+func (e *Environment) SetOutput(out io.Writer) {
+	e.root().out = out
+}
+
+func (e *Environment) Input() *bufio.Reader {
+	return e.root().in
+}
+
+func (e *Environment) SetInput(in *bufio.Reader) {
+	e.root().in = in
+}
+
+func (e *Environment) ContractsEnabled() bool {
+	return e.root().contracts
+}
+
+func (e *Environment) SetContracts(enabled bool) {
+	e.root().contracts = enabled
+}
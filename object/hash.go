@@ -0,0 +1,92 @@
+package object
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// ハッシュのキーにできる値を、比べられる形にしたもの
+// 1 == 1.0 なので、整数になる浮動小数点数は整数と同じキーにする
+type HashKey struct {
+	Type ObjectType
+	Value string
+}
+
+type HashPair struct {
+	Key Object
+	Value Object
+}
+
+type Hash struct {
+	Pairs map[HashKey]HashPair
+	// 表示したときに書いた順になるよう、入れた順を覚えておく
+	Order []HashKey
+	// create(proto) で作ったときのプロトタイプ。なければnil
+	Proto *Hash
+}
+
+func NewHash() *Hash {
+	return &Hash{Pairs: map[HashKey]HashPair{ }}
+}
+func (h *Hash) String() string {
+	return newPrinter().print(h)
+}
+func (h *Hash) Type() ObjectType {
+	return HASH_OBJ
+}
+
+// キーにできない値ならエラーを返す
+func (h *Hash) Set(key Object, value Object) Object {
+	hashKey, err := NewHashKey(key)
+	if err != nil {
+		return err
+	}
+	// もうあるキーなら、キーは最初に入れたものを残して値だけ変える
+	if pair, ok := h.Pairs[hashKey]; ok {
+		key = pair.Key
+	} else {
+		h.Order = append(h.Order, hashKey)
+	}
+	h.Pairs[hashKey] = HashPair{Key: key, Value: value}
+	return nil
+}
+
+func (h *Hash) Get(key Object) (Object, bool) {
+	hashKey, err := NewHashKey(key)
+	if err != nil {
+		return nil, false
+	}
+	pair, ok := h.Pairs[hashKey]
+	return pair.Value, ok
+}
+
+// 自分になければプロトタイプをたどって探す
+func (h *Hash) Lookup(key Object) (Object, bool) {
+	for hash := h; hash != nil; hash = hash.Proto {
+		if val, ok := hash.Get(key); ok {
+			return val, true
+		}
+	}
+	return nil, false
+}
+
+func NewHashKey(key Object) (HashKey, Object) {
+	switch key := key.(type) {
+	case *Integer, *BigInteger:
+		return HashKey{Type: INTEGER_OBJ, Value: key.String()}, nil
+	case *Float:
+		if !math.IsInf(key.Value, 0) && key.Value == math.Trunc(key.Value) {
+			value, _ := big.NewFloat(key.Value).Int(nil)
+			return HashKey{Type: INTEGER_OBJ, Value: value.String()}, nil
+		}
+		return HashKey{Type: FLOAT_OBJ, Value: key.String()}, nil
+	case *String:
+		return HashKey{Type: STRING_OBJ, Value: key.Value}, nil
+	case *Boolean:
+		return HashKey{Type: BOOLEAN_OBJ, Value: key.String()}, nil
+	case *Null:
+		return HashKey{Type: NULL_OBJ}, nil
+	}
+	return HashKey{ }, &OtherError{Msg: fmt.Sprintf("%s is not hashable", key.Type())}
+}
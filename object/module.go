@@ -0,0 +1,173 @@
+package object
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// import("lib/math.yk") で読み込んだファイル。トップレベルで代入した名前を . で取り出せる
+type Module struct {
+	// importに渡したパス
+	Name string
+	// 見つけたファイルの絶対パス
+	Path string
+	Env *Environment
+}
+
+func (m *Module) String() string {
+	return "module "+m.Name+"("+strings.Join(m.Names(), ", ")+")"
+}
+func (m *Module) Type() ObjectType {
+	return MODULE_OBJ
+}
+
+// 組み込みは含めず、そのファイルで代入した名前だけを探す
+func (m *Module) Get(name string) (Object, bool) {
+	obj, ok := m.Env.store[name]
+	return obj, ok
+}
+
+func (m *Module) Names() []string {
+	var names []string
+	for name := range m.Env.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// インタプリタひとつにつきひとつ。importしたファイルは一度だけ評価する
+type modules struct {
+	loaded map[string]*Module
+	// 評価している途中のファイル。循環を見つけるのに使う
+	loading []*Module
+}
+
+func newModules() *modules {
+	return &modules{loaded: map[string]*Module{ }}
+}
+
+// 実行しているファイル。importはこのファイルのディレクトリから探す
+func (e *Environment) SetFile(path string) {
+	root := e.root()
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	root.file = abs
+	root.modules.loading = append(root.modules.loading, &Module{Name: path, Path: abs})
+}
+
+var moduleBuildins = map[string]*Buildin{
+	"import": &Buildin{
+		Signature: &Signature{
+			Params: []Param{
+				{Name: "path", Types: stringTypes},
+			},
+			Doc: "Evaluate the file at path once and return its top-level bindings as a module.",
+		},
+		ContextFn: func(ctx *Context, args ...Object) Object {
+			return importModule(ctx, args[0].(*String).Value)
+		},
+	},
+}
+
+// importしたファイルで起きたエラー。どのファイルかを添える
+type ImportError struct {
+	Name string
+	Err Object
+}
+func (e *ImportError) ErrorObject() { }
+func (e *ImportError) String() string {
+	return fmt.Sprintf("error in %s: %s", e.Name, e.Err.String())
+}
+func (e *ImportError) Type() ObjectType {
+	return ERROR_OBJ
+}
+
+func importModule(ctx *Context, name string) Object {
+	importer := ctx.Env.root()
+	path, err := resolveModule(name, importer.file)
+	if err != nil { return err }
+	mods := importer.modules
+	if module, ok := mods.loaded[path]; ok {
+		return module
+	}
+	for i, loading := range mods.loading {
+		if loading.Path == path {
+			var cycle []string
+			for _, m := range mods.loading[i:] {
+				cycle = append(cycle, m.Name)
+			}
+			cycle = append(cycle, name)
+			return &OtherError{Msg: "import cycle: "+strings.Join(cycle, " -> ")}
+		}
+	}
+
+	program, errors, readErr := ctx.Load(path)
+	if readErr != nil {
+		return &OtherError{Msg: fmt.Sprintf("import could not read %s: %s", name, readErr)}
+	}
+	if len(errors) != 0 {
+		return &OtherError{Msg: fmt.Sprintf("import could not parse %s: %s", name, strings.Join(errors, ", "))}
+	}
+
+	module := &Module{Name: name, Path: path, Env: newModuleEnvironment(importer, path)}
+	mods.loading = append(mods.loading, module)
+	result := ctx.Eval(program, module.Env)
+	mods.loading = mods.loading[:len(mods.loading)-1]
+	if isError(result) {
+		// 一番内側のファイルの名前だけを添える
+		if _, ok := result.(*ImportError); ok {
+			return result
+		}
+		return &ImportError{Name: name, Err: result}
+	}
+	mods.loaded[path] = module
+	return module
+}
+
+// importしたファイルを評価する環境
+// 組み込みは新しく用意し、入出力と乱数の状態と読み込んだモジュールはimportしたところと共有する
+func newModuleEnvironment(importer *Environment, path string) *Environment {
+	root := newEnvironment(importer.random)
+	root.out = importer.out
+	root.in = importer.in
+	root.contracts = importer.contracts
+	root.modules = importer.modules
+	root.file = path
+	return NewInferitEnvironment(root)
+}
+
+// importしたファイルのディレクトリ(REPLならカレントディレクトリ)、YOKAN_PATHのディレクトリの順に探す
+func resolveModule(name string, from string) (string, Object) {
+	if filepath.IsAbs(name) {
+		if info, err := os.Stat(name); err == nil && !info.IsDir() {
+			return filepath.Clean(name), nil
+		}
+		return "", &OtherError{Msg: fmt.Sprintf("module %s not found", name)}
+	}
+	dirs := []string{"."}
+	if from != "" {
+		dirs[0] = filepath.Dir(from)
+	}
+	for _, dir := range filepath.SplitList(os.Getenv("YOKAN_PATH")) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	for _, dir := range dirs {
+		candidate := filepath.Join(dir, name)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			abs, err := filepath.Abs(candidate)
+			if err != nil {
+				return "", &OtherError{Msg: fmt.Sprintf("import could not resolve %s: %s", name, err)}
+			}
+			return abs, nil
+		}
+	}
+	return "", &OtherError{Msg: fmt.Sprintf("module %s not found in %s", name, strings.Join(dirs, ", "))}
+}
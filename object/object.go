@@ -2,6 +2,7 @@ package object
 
 import (
 	"fmt"
+	"math/big"
 	"yokan/ast"
 	"yokan/utility"
 )
@@ -13,9 +14,16 @@ const (
 	BUILDIN_OBJ = "BUILDIN"
 
 	INTEGER_OBJ = "INTEGER"
+	FLOAT_OBJ = "FLOAT"
 	STRING_OBJ = "STRING"
 	BOOLEAN_OBJ = "BOOLEAN"
 	NULL_OBJ = "NULL"
+	ARRAY_OBJ = "ARRAY"
+	HASH_OBJ = "HASH"
+	STRUCT_OBJ = "STRUCT"
+	STRUCT_TYPE_OBJ = "STRUCT_TYPE"
+	ENUM_OBJ = "ENUM"
+	MODULE_OBJ = "MODULE"
 	
 	ERROR_OBJ = "ERROR"
 	SHOULD_NOT_VIEWABLE_OBJ = "SHOULD_NOT_VIEWABLE"
@@ -29,29 +37,67 @@ type Object interface {
 
 type Function struct {
 	Parameters []ast.Identifier
+	// Parametersと同じ長さで、初期値のない引数のところはnil
+	Defaults []ast.Expression
+	// Parametersと同じ長さで、分割して受け取る引数のところだけパターンが入る
+	Patterns []ast.Pattern
+	// 残りの引数を配列で受け取る引数。なければnil
+	Rest *ast.Identifier
+	// 呼ぶ前と後に調べる条件
+	Requires []ast.Expression
+	Ensures []ast.Expression
+	// 代入した変数の名前。無名関数なら空
+	Name string
 	Body []ast.Statement
 	// 実行時じゃなくて定義時の環境を持たないといけないので、Functionが環境を保つ必要がある
 	Env *Environment
 }
 func (f *Function) String() string {
-	var param []string
-	for _, p := range f.Parameters {
-		param = append(param, p.Name)
-	}
-	var body []string
+	param := ast.ParameterStrings(f.Parameters, f.Defaults, f.Rest)
+	body := ast.ContractStrings(f.Requires, f.Ensures)
 	for _, b := range f.Body {
 		body = append(body, b.String())
 	}
 	return utility.FunctionString(param, body)
 }
+
+// 受け取れる引数の数の範囲。上限がなければmaxは-1
+func (f *Function) Arity() (int, int) {
+	min := 0
+	for i := range f.Parameters {
+		if i >= len(f.Defaults) || f.Defaults[i] == nil {
+			min += 1
+		}
+	}
+	if f.Rest != nil {
+		return min, -1
+	}
+	return min, len(f.Parameters)
+}
 func (f *Function) Type() ObjectType {
 	return FUNCTION_OBJ
 }
 
 type BuildinFunction func(args ...Object) Object
 
+// 普通はFnだけを使う
+// 呼び出し元の状況が必要なものはContextFnを使う
 type Buildin struct {
 	Fn BuildinFunction
+	ContextFn ContextBuildinFunction
+	// nilでなければ、呼ぶ前に引数を調べる
+	Signature *Signature
+}
+func (b *Buildin) Call(ctx *Context, args []Object) Object {
+	if b.Signature != nil {
+		if err := b.Signature.Check(args); err != nil {
+			return err
+		}
+	}
+	if b.ContextFn != nil {
+		return b.ContextFn(ctx, args...)
+	}
+	return b.Fn(args...)
 }
 func (b *Buildin) String() string {
 	return "<buildin function>"
@@ -73,6 +119,36 @@ func (i *Integer) Type() ObjectType {
 	return INTEGER_OBJ
 }
 
+// int64に収まらない整数
+// 型はIntegerと同じINTEGERとして見せ、計算結果がint64に収まるときはIntegerに戻す
+type BigInteger struct {
+	Value *big.Int
+}
+func (b *BigInteger) String() string {
+	return b.Value.String()
+}
+func (b *BigInteger) Type() ObjectType {
+	return INTEGER_OBJ
+}
+
+// int64に収まるならInteger、収まらなければBigIntegerを返す
+func NewInteger(value *big.Int) Object {
+	if value.IsInt64() {
+		return &Integer{Value: value.Int64()}
+	}
+	return &BigInteger{Value: value}
+}
+
+type Float struct {
+	Value float64
+}
+func (f *Float) String() string {
+	return utility.FloatString(f.Value)
+}
+func (f *Float) Type() ObjectType {
+	return FLOAT_OBJ
+}
+
 type String struct {
 	Value string
 }
@@ -97,6 +173,16 @@ func (b *Boolean) Type() ObjectType {
 	return BOOLEAN_OBJ
 }
 
+type Array struct {
+	Elements []Object
+}
+func (a *Array) String() string {
+	return newPrinter().print(a)
+}
+func (a *Array) Type() ObjectType {
+	return ARRAY_OBJ
+}
+
 type Null struct { }
 func (n *Null) String() string {
 	return "null"
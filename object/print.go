@@ -0,0 +1,92 @@
+package object
+
+import (
+	"bytes"
+)
+
+// 自分自身を含む配列やハッシュでも止まるよう、表示している途中の値を覚えておく
+type printer struct {
+	visiting map[Object]bool
+}
+
+func newPrinter() *printer {
+	return &printer{visiting: map[Object]bool{ }}
+}
+
+// 表示している途中の値にもう一度来たら、中身は ... にする
+func (p *printer) print(obj Object) string {
+	switch obj := obj.(type) {
+	case *Array:
+		if p.visiting[obj] {
+			return "[...]"
+		}
+		p.visiting[obj] = true
+		defer delete(p.visiting, obj)
+		return p.printArray(obj)
+	case *Hash:
+		if p.visiting[obj] {
+			return "{...}"
+		}
+		p.visiting[obj] = true
+		defer delete(p.visiting, obj)
+		return p.printHash(obj)
+	case *Struct:
+		if p.visiting[obj] {
+			return obj.Def.Name+"(...)"
+		}
+		p.visiting[obj] = true
+		defer delete(p.visiting, obj)
+		return p.printStruct(obj)
+	}
+	return obj.String()
+}
+
+func (p *printer) printArray(a *Array) string {
+	var out bytes.Buffer
+	out.WriteString("[")
+	len := len(a.Elements)
+	for i, e := range a.Elements {
+		out.WriteString(p.print(e))
+		if i+1 != len {
+			out.WriteString(", ")
+		}
+	}
+	out.WriteString("]")
+	return out.String()
+}
+
+func (p *printer) printHash(h *Hash) string {
+	var out bytes.Buffer
+	out.WriteString("{")
+	for i, key := range h.Order {
+		if i != 0 {
+			out.WriteString(", ")
+		}
+		pair := h.Pairs[key]
+		out.WriteString(p.print(pair.Key))
+		out.WriteString(": ")
+		out.WriteString(p.print(pair.Value))
+	}
+	out.WriteString("}")
+	return out.String()
+}
+
+func (p *printer) printStruct(s *Struct) string {
+	// 列挙型の Empty のようなカッコのない値は名前だけ
+	if s.Def.Enum != nil && s.Def.Enum.IsUnit(s.Def.Name) {
+		return s.Def.Name
+	}
+	var out bytes.Buffer
+	out.WriteString(s.Def.Name)
+	out.WriteString("(")
+	for i, f := range s.Def.Fields {
+		if i != 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(f)
+		out.WriteString(": ")
+		out.WriteString(p.print(s.Values[i]))
+	}
+	out.WriteString(")")
+	return out.String()
+}
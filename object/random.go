@@ -0,0 +1,90 @@
+package object
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+)
+
+// 乱数の組み込み関数
+// インタプリタごとに別々の乱数の状態を持てるよう、環境を作るたびに作り直す
+func newRandomBuildins(r *rand.Rand) map[string]*Buildin {
+	return map[string]*Buildin{
+		"random": &Buildin{
+			Signature: &Signature{
+				Doc: "Return a random float in [0, 1).",
+			},
+			Fn: func(args ...Object) Object {
+				return &Float{Value: r.Float64()}
+			},
+		},
+		"random_int": &Buildin{
+			Signature: &Signature{
+				Params: []Param{
+					{Name: "lo", Types: integerTypes},
+					{Name: "hi", Types: integerTypes},
+				},
+				Doc: "Return a random integer from lo to hi, both inclusive.",
+			},
+			Fn: func(args ...Object) Object {
+				lo := ToBig(args[0])
+				hi := ToBig(args[1])
+				if lo.Cmp(hi) > 0 {
+					return &OtherError{Msg: fmt.Sprintf("random_int lower bound %s is greater than upper bound %s", lo, hi)}
+				}
+				// lo以上hi以下
+				width := new(big.Int).Sub(hi, lo)
+				width.Add(width, big.NewInt(1))
+				return NewInteger(new(big.Int).Add(lo, new(big.Int).Rand(r, width)))
+			},
+		},
+		"shuffle": &Buildin{
+			Signature: &Signature{
+				Params: []Param{
+					{Name: "arr", Types: arrayTypes},
+				},
+				Doc: "Return a shuffled copy of arr.",
+			},
+			Fn: func(args ...Object) Object {
+				arr := args[0].(*Array)
+				elements := make([]Object, len(arr.Elements))
+				copy(elements, arr.Elements)
+				r.Shuffle(len(elements), func(i, j int) {
+					elements[i], elements[j] = elements[j], elements[i]
+				})
+				return &Array{Elements: elements}
+			},
+		},
+		"choice": &Buildin{
+			Signature: &Signature{
+				Params: []Param{
+					{Name: "arr", Types: arrayTypes},
+				},
+				Doc: "Return a random element of arr.",
+			},
+			Fn: func(args ...Object) Object {
+				arr := args[0].(*Array)
+				if len(arr.Elements) == 0 {
+					return &OtherError{Msg: "choice from empty array"}
+				}
+				return arr.Elements[r.Intn(len(arr.Elements))]
+			},
+		},
+		"seed": &Buildin{
+			Signature: &Signature{
+				Params: []Param{
+					{Name: "seed", Types: integerTypes},
+				},
+				Doc: "Reset the random generator of this interpreter with seed.",
+			},
+			Fn: func(args ...Object) Object {
+				seed, err := toInt64("seed", args[0])
+				if err != nil {
+					return err
+				}
+				r.Seed(seed)
+				return &Null{ }
+			},
+		},
+	}
+}
@@ -0,0 +1,165 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// 組み込み関数の引数の宣言
+// 引数の数や型はこれをもとに呼び出す前に調べるので、組み込み関数の中で調べなくてよい
+type Signature struct {
+	// 登録するときに入れる
+	Name string
+	Params []Param
+	Doc string
+}
+
+type Param struct {
+	Name string
+	// 空なら何でもよい
+	Types []ObjectType
+	// 省略できる(後ろの引数だけ)
+	Optional bool
+	// 残りの引数をすべて受け取る(最後の引数だけ)
+	Variadic bool
+}
+
+var numberTypes = []ObjectType{INTEGER_OBJ, FLOAT_OBJ}
+var callableTypes = []ObjectType{FUNCTION_OBJ, BUILDIN_OBJ, STRUCT_TYPE_OBJ}
+
+// 引数の数と型が宣言に合っているか調べて、合っていなければエラーを返す
+func (s *Signature) Check(args []Object) Object {
+	min, max := s.arity()
+	if len(args) < min || max >= 0 && len(args) > max {
+		return &OtherError{Msg: fmt.Sprintf("%s need %s arguments. but got %d", s.Name, s.arityString(), len(args))}
+	}
+	for i, arg := range args {
+		param := s.param(i)
+		if !acceptsType(param.Types, arg.Type()) {
+			return &TypeMisMatchError{
+				Name: fmt.Sprintf("%s(%s)", s.Name, param.Name),
+				Expected: typesString(param.Types),
+				Got: arg,
+			}
+		}
+	}
+	return nil
+}
+
+// 位置で渡した引数argsのあとに、名前付きで渡した引数を宣言の位置に並べる
+// 組み込み関数は引数を詰めて受け取るので、途中の引数は省略できない
+func (s *Signature) BindKeywords(args []Object, names []string, values []Object) ([]Object, Object) {
+	bound := append([]Object{ }, args...)
+	for i, name := range names {
+		idx := -1
+		for j, param := range s.Params {
+			if param.Name == name && !param.Variadic {
+				idx = j
+			}
+		}
+		if idx < 0 {
+			return nil, &OtherError{Msg: fmt.Sprintf("%s got an unknown keyword argument %s", s.Name, name)}
+		}
+		for len(bound) <= idx {
+			bound = append(bound, nil)
+		}
+		if bound[idx] != nil {
+			return nil, &OtherError{Msg: fmt.Sprintf("%s got multiple values for argument %s", s.Name, name)}
+		}
+		bound[idx] = values[i]
+	}
+	for i, arg := range bound {
+		if arg == nil {
+			return nil, &OtherError{Msg: fmt.Sprintf("%s missing argument %s", s.Name, s.Params[i].Name)}
+		}
+	}
+	return bound, nil
+}
+
+// split(str: STRING, sep: STRING) のように書く
+func (s *Signature) String() string {
+	var out bytes.Buffer
+	out.WriteString(s.Name)
+	out.WriteString("(")
+	for i, param := range s.Params {
+		if i != 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(param.Name)
+		if param.Optional {
+			out.WriteString("?")
+		}
+		if param.Variadic {
+			out.WriteString("...")
+		}
+		if len(param.Types) != 0 {
+			out.WriteString(": ")
+			out.WriteString(strings.Replace(typesString(param.Types), ", ", "|", -1))
+		}
+	}
+	out.WriteString(")")
+	return out.String()
+}
+
+// 受け取れる引数の数の範囲。上限がなければmaxは-1
+func (s *Signature) arity() (int, int) {
+	min := 0
+	max := 0
+	for _, param := range s.Params {
+		if param.Variadic {
+			return min, -1
+		}
+		if !param.Optional {
+			min += 1
+		}
+		max += 1
+	}
+	return min, max
+}
+
+func (s *Signature) arityString() string {
+	return ArityString(s.arity())
+}
+
+// 引数の数の範囲を "2", "1 or 2", "1 to 3", "at least 1" のように書く
+func ArityString(min int, max int) string {
+	switch {
+	case max < 0:
+		return fmt.Sprintf("at least %d", min)
+	case min == max:
+		return fmt.Sprintf("%d", min)
+	case min+1 == max:
+		return fmt.Sprintf("%d or %d", min, max)
+	default:
+		return fmt.Sprintf("%d to %d", min, max)
+	}
+}
+
+// i番目の引数の宣言
+func (s *Signature) param(i int) Param {
+	if i >= len(s.Params) {
+		return s.Params[len(s.Params)-1]
+	}
+	return s.Params[i]
+}
+
+func acceptsType(types []ObjectType, t ObjectType) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, accepted := range types {
+		if accepted == t {
+			return true
+		}
+	}
+	return false
+}
+
+func typesString(types []ObjectType) string {
+	var names []string
+	for _, t := range types {
+		names = append(names, string(t))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,139 @@
+package object
+
+import (
+	"strings"
+)
+
+// struct(x, y) で作る型。呼び出すとその型の値を作る
+type StructType struct {
+	// 名前を付けずに作ったときは"struct"
+	Name string
+	Fields []string
+	// 引数の数と名前付き引数は組み込み関数と同じように調べる
+	Signature *Signature
+	// Point.norm = () => ... で付けた、すべての値で共有するメソッド
+	Methods map[string]Object
+	// 列挙型のひとつの値のときだけ、その列挙型が入る
+	Enum *EnumType
+}
+
+func NewStructType(name string, fields []string) *StructType {
+	if name == "" {
+		name = "struct"
+	}
+	sig := &Signature{Name: name, Doc: "Create a "+name+"."}
+	for _, f := range fields {
+		sig.Params = append(sig.Params, Param{Name: f})
+	}
+	return &StructType{Name: name, Fields: fields, Signature: sig, Methods: map[string]Object{ }}
+}
+
+func (st *StructType) String() string {
+	if st.Enum != nil {
+		return st.Enum.Name+"."+st.Name+"("+strings.Join(st.Fields, ", ")+")"
+	}
+	return "struct "+st.Name+"("+strings.Join(st.Fields, ", ")+")"
+}
+func (st *StructType) Type() ObjectType {
+	return STRUCT_TYPE_OBJ
+}
+
+func (st *StructType) New(values []Object) *Struct {
+	return &Struct{Def: st, Values: values}
+}
+
+// 構造体の値。ValuesはDef.Fieldsと同じ順に並ぶ
+type Struct struct {
+	Def *StructType
+	Values []Object
+}
+
+func (s *Struct) String() string {
+	return newPrinter().print(s)
+}
+func (s *Struct) Type() ObjectType {
+	return STRUCT_OBJ
+}
+
+func (s *Struct) Get(field string) (Object, bool) {
+	i := s.fieldIndex(field)
+	if i < 0 {
+		return nil, false
+	}
+	return s.Values[i], true
+}
+
+// ないフィールドならfalseを返す
+func (s *Struct) Set(field string, value Object) bool {
+	i := s.fieldIndex(field)
+	if i < 0 {
+		return false
+	}
+	s.Values[i] = value
+	return true
+}
+
+// フィールドになければ型のメソッドを探す
+func (s *Struct) Lookup(name string) (Object, bool) {
+	if val, ok := s.Get(name); ok {
+		return val, true
+	}
+	method, ok := s.Def.Methods[name]
+	return method, ok
+}
+
+func (s *Struct) fieldIndex(field string) int {
+	for i, f := range s.Def.Fields {
+		if f == field {
+			return i
+		}
+	}
+	return -1
+}
+
+// enum Shape { Circle(r), Rect(w, h), Empty } で作る型
+// カッコのある値は構造体の型として呼び出して作り、カッコのない値ははじめからひとつだけ作っておく
+type EnumType struct {
+	Name string
+	// 書いた順に並べる
+	Names []string
+	// 値はそれぞれ*StructTypeか、カッコのない値なら*Struct
+	Variants map[string]Object
+}
+
+func NewEnumType(name string) *EnumType {
+	return &EnumType{Name: name, Variants: map[string]Object{ }}
+}
+
+// fieldsがnilならカッコのない値を作る
+func (et *EnumType) AddVariant(name string, fields []string) Object {
+	st := NewStructType(name, fields)
+	st.Enum = et
+	var variant Object = st
+	if fields == nil {
+		variant = st.New([]Object{ })
+	}
+	et.Names = append(et.Names, name)
+	et.Variants[name] = variant
+	return variant
+}
+
+func (et *EnumType) IsUnit(name string) bool {
+	_, ok := et.Variants[name].(*Struct)
+	return ok
+}
+
+func (et *EnumType) String() string {
+	var variants []string
+	for _, name := range et.Names {
+		if st, ok := et.Variants[name].(*StructType); ok {
+			variants = append(variants, name+"("+strings.Join(st.Fields, ", ")+")")
+		} else {
+			variants = append(variants, name)
+		}
+	}
+	return "enum "+et.Name+" { "+strings.Join(variants, ", ")+" }"
+}
+func (et *EnumType) Type() ObjectType {
+	return ENUM_OBJ
+}
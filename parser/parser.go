@@ -2,7 +2,9 @@ package parser
 
 import (
 	"fmt"
+	"math/big"
 	"strconv"
+	"strings"
 	"yokan/ast"
 	"yokan/lexer"
 	"yokan/token"
@@ -12,27 +14,42 @@ type Parser struct {
 	l *lexer.Lexer
 	errors []string
 
+	// 関数リテラルかどうかは閉じカッコの先まで見ないとわからないので、先にすべて字句解析しておく
+	tokens []token.Token
+	position int
+	// matchのガードを読んでいる間は、ガードの終わりの => の位置。それ以外は-1
+	guardEnd int
+
 	curToken  token.Token
 	peekToken token.Token
-	peek2Token token.Token
-	peek3Token token.Token
 }
 
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
 		l: l,
 		errors: []string {},
+		guardEnd: -1,
 	}
-	p.nextToken()
-	p.nextToken()
+	for {
+		tok := l.NextToken()
+		p.tokens = append(p.tokens, tok)
+		if tok.Type == token.EOF {
+			break
+		}
+	}
+	p.position = -2
 	p.nextToken()
 	p.nextToken()
 
 	return p
 }
 
+// 字句解析でのエラー(書き間違えた数値リテラルなど)も合わせて返す
 func (p *Parser) Errors() []string {
-	return p.errors
+	var errors []string
+	errors = append(errors, p.l.Errors()...)
+	errors = append(errors, p.errors...)
+	return errors
 }
 
 func (p *Parser) peekError(t token.TokenType) {
@@ -46,10 +63,20 @@ func (p *Parser) appendError(msg string) {
 }
 
 func (p *Parser) nextToken() {
-	p.curToken = p.peekToken
-	p.peekToken = p.peek2Token
-	p.peek2Token = p.peek3Token
-	p.peek3Token = p.l.NextToken()
+	p.position += 1
+	p.curToken = p.tokenAt(p.position)
+	p.peekToken = p.tokenAt(p.position+1)
+}
+
+// 最後より後ろはずっとEOFが続く
+func (p *Parser) tokenAt(position int) token.Token {
+	if position < 0 {
+		return token.Token{ }
+	}
+	if position >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[position]
 }
 
 func (p *Parser) ParseProgram() *ast.Program {
@@ -76,27 +103,225 @@ func (p *Parser) parseStatement() ast.Statement {
 	case token.NEWLINE:
 		return nil
 	case token.IDENT:
-		if p.peekTokenIs(token.ASSIGN) {
+		if p.peekTokenIs(token.ASSIGN) || p.isAnnotatedAssign() {
 			return p.parseAssign()
 		} else {
 			expr = p.parseExpression()
 		}
+	case token.ENUM:
+		return p.parseEnumStatement()
+	case token.REQUIRES, token.ENSURES:
+		p.appendError(fmt.Sprintf("'%s' must be at the beginning of a function body", p.curToken.Literal))
+		return nil
+	case token.LBRACK, token.LBRACE:
+		if p.isDestructuringAssign() {
+			return p.parseDestructuringAssign()
+		}
+		expr = p.parseExpression()
 	default:
 		expr = p.parseExpression()
 	}
+	// arr[i] = v や p.x = v や x += 1 は左辺を式として読んでから決める
+	if p.peekTokenIs(token.ASSIGN) {
+		return p.parseElementAssign(expr)
+	}
+	if operator, ok := compoundAssignOperators[p.peekToken.Type]; ok {
+		return p.parseCompoundAssign(expr, operator)
+	}
 	return &ast.ExpressionStatement{Expression: expr}
 }
 
-func (p *Parser) parseAssign() *ast.Assign {
+var compoundAssignOperators = map[token.TokenType]string {
+	token.PLUS_ASSIGN: "+",
+	token.MINUS_ASSIGN: "-",
+	token.STAR_ASSIGN: "*",
+	token.SLASH_ASSIGN: "/",
+	token.PERCENT_ASSIGN: "%",
+}
+
+// curTokenが左辺の最後のとき、= の後ろを読む
+func (p *Parser) parseElementAssign(target ast.Expression) ast.Statement {
+	switch target := target.(type) {
+	case *ast.IndexExpression:
+		p.nextToken()
+		ia := &ast.IndexAssign{Token: p.curToken, Target: target}
+		ia.Value = p.parseAssignedValue(target)
+		if ia.Value == nil {
+			return nil
+		}
+		return ia
+	case *ast.MemberExpression:
+		p.nextToken()
+		ma := &ast.MemberAssign{Token: p.curToken, Target: target}
+		ma.Value = p.parseAssignedValue(target)
+		if ma.Value == nil {
+			return nil
+		}
+		if fl, ok := ma.Value.(*ast.FunctionLiteral); ok && fl.Name == "" {
+			fl.Name = target.String()
+		}
+		return ma
+	}
+	p.appendError(fmt.Sprintf("cannot assign to %s", expressionString(target)))
+	return nil
+}
+
+// curTokenが = のとき、その後ろの式を読む
+func (p *Parser) parseAssignedValue(target ast.Expression) ast.Expression {
+	p.nextToken()
+	value := p.parseExpression()
+	if value == nil {
+		p.appendError(fmt.Sprintf("expected expression after '%s ='", expressionString(target)))
+	}
+	return value
+}
+
+func (p *Parser) parseCompoundAssign(target ast.Expression, operator string) ast.Statement {
+	switch target.(type) {
+	case *ast.Identifier, *ast.IndexExpression, *ast.MemberExpression:
+	default:
+		p.appendError(fmt.Sprintf("cannot assign to %s", expressionString(target)))
+		return nil
+	}
+	p.nextToken()
+	ca := &ast.CompoundAssign{Token: p.curToken, Target: target, Operator: operator}
+	p.nextToken()
+	ca.Value = p.parseExpression()
+	if ca.Value == nil {
+		p.appendError(fmt.Sprintf("expected expression after '%s'", ca.Token.Literal))
+		return nil
+	}
+	return ca
+}
+
+func expressionString(expr ast.Expression) string {
+	if expr == nil {
+		return "nothing"
+	}
+	return expr.String()
+}
+
+// x: int = 1
+func (p *Parser) isAnnotatedAssign() bool {
+	return p.peekTokenIs(token.COLON) && p.tokenAt(p.position+2).Type == token.IDENT && p.tokenAt(p.position+3).Type == token.ASSIGN
+}
+
+// 右辺が読めなければ、型付きのnilにならないようast.Statementのnilを返す
+func (p *Parser) parseAssign() ast.Statement {
 	assign := &ast.Assign{Name: *p.parseIdentifier()}
+	target := assign.Name.Name
 	p.nextToken()
+	if p.curTokenIs(token.COLON) {
+		p.nextToken()
+		assign.Name.Type = p.parseTypeName()
+		target += ": "+p.curToken.Literal
+		p.nextToken()
+	}
 	p.nextToken()
+	errors := len(p.errors)
 	assign.Value = p.parseExpression()
+	if assign.Value == nil {
+		// 右辺を読むときや字句解析でエラーになっていれば、そちらだけを報告する
+		if len(p.errors) == errors && !p.curTokenIs(token.ILLEGAL) {
+			p.appendError(fmt.Sprintf("expected expression after '%s ='", target))
+		}
+		return nil
+	}
+	// Point = struct(x, y) の構造体はPointという名前になる
+	if sl, ok := assign.Value.(*ast.StructLiteral); ok && sl.Name == "" {
+		sl.Name = assign.Name.Name
+	}
+	if fl, ok := assign.Value.(*ast.FunctionLiteral); ok && fl.Name == "" {
+		fl.Name = assign.Name.Name
+	}
 	return assign
 }
 
+// [a, b] = pair や {"name": n} = record
+func (p *Parser) parseDestructuringAssign() ast.Statement {
+	da := &ast.DestructuringAssign{Token: p.curToken, Pattern: p.parsePattern()}
+	if da.Pattern == nil || !p.expectPeek(token.ASSIGN) {
+		return nil
+	}
+	p.nextToken()
+	da.Value = p.parseExpression()
+	if da.Value == nil {
+		p.appendError(fmt.Sprintf("expected expression after '%s ='", da.Pattern.String()))
+		return nil
+	}
+	return da
+}
+
+// curTokenの [ か { に対応する閉じカッコのすぐ後ろが = なら分割代入
+func (p *Parser) isDestructuringAssign() bool {
+	end := p.findClosing(p.position)
+	return end != -1 && p.tokenAt(end+1).Type == token.ASSIGN
+}
+
+// startのカッコに対応する閉じカッコの位置。なければ-1
+func (p *Parser) findClosing(start int) int {
+	depth := 0
+	for i := start; i < len(p.tokens); i++ {
+		switch p.tokens[i].Type {
+		case token.LPAREN, token.LBRACK, token.LBRACE:
+			depth += 1
+		case token.RPAREN, token.RBRACK, token.RBRACE:
+			depth -= 1
+			if depth == 0 {
+				return i
+			}
+		case token.EOF:
+			return -1
+		}
+	}
+	return -1
+}
+
 func (p *Parser) parseExpression() ast.Expression {
-	return p.parseEqExpression()
+	return p.parsePipeExpression()
+}
+
+// x |> f(a) を f(x, a) に、x |> f を f(x) に読み替える
+// 行の頭に |> を書いて、前の行から続けることもできる
+func (p *Parser) parsePipeExpression() ast.Expression {
+	// |> f のように左に何もない
+	if p.curTokenIs(token.PIPE) {
+		p.appendError("expected expression around '|>'")
+		return nil
+	}
+	expr := p.parseEqExpression()
+	for p.peekTokenIs(token.PIPE) || p.peekTokenIs(token.NEWLINE) && p.isPipeAfterNewlines() {
+		for p.peekTokenIs(token.NEWLINE) {
+			p.nextToken()
+		}
+		p.nextToken()
+		pipe := p.curToken
+		p.nextToken()
+		right := p.parseEqExpression()
+		if expr == nil || right == nil {
+			p.appendError("expected expression around '|>'")
+			return nil
+		}
+		if call, ok := right.(*ast.FunctionCalling); ok {
+			call.Arguments = append([]ast.Expression{expr}, call.Arguments...)
+			expr = call
+		} else {
+			expr = &ast.FunctionCalling{
+				Token: pipe,
+				Function: right,
+				Arguments: []ast.Expression{expr},
+			}
+		}
+	}
+	return expr
+}
+
+func (p *Parser) isPipeAfterNewlines() bool {
+	i := p.position+1
+	for p.tokenAt(i).Type == token.NEWLINE {
+		i += 1
+	}
+	return p.tokenAt(i).Type == token.PIPE
 }
 
 func (p *Parser) parseEqExpression() ast.Expression {
@@ -151,7 +376,7 @@ func (p *Parser) parseAddSubExpression() ast.Expression {
 
 func (p *Parser) parseMulDivExpression() ast.Expression {
 	expr := p.parsePrefixExpression()
-	for p.peekTokenIs(token.STAR) || p.peekTokenIs(token.SLASH) {
+	for p.peekTokenIs(token.STAR) || p.peekTokenIs(token.SLASH) || p.peekTokenIs(token.PERCENT) {
 		p.nextToken()
 		newExpr := &ast.InfixExpression{
 			Token: p.curToken,
@@ -176,39 +401,145 @@ func (p *Parser) parsePrefixExpression() ast.Expression {
 	return pe
 }
 
+// f(a)(b) や arr[i][j] や p.x のように、後ろに続く呼び出しと添字とフィールドを読む
 func (p *Parser) parseFunctionCalling() ast.Expression {
 	expr := p.parseFunctionLiteral()
-	for p.peekTokenIs(token.LPAREN) {
+	for p.peekTokenIs(token.LPAREN) || p.peekTokenIs(token.LBRACK) || p.peekTokenIs(token.DOT) {
 		p.nextToken()
+		if p.curTokenIs(token.DOT) {
+			me := &ast.MemberExpression{Token: p.curToken, Object: expr}
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			me.Member = *p.parseIdentifier()
+			expr = me
+			continue
+		}
+		if p.curTokenIs(token.LBRACK) {
+			ie := &ast.IndexExpression{Token: p.curToken, Left: expr}
+			p.nextToken()
+			ie.Index = p.parseExpression()
+			if ie.Index == nil || !p.expectPeek(token.RBRACK) {
+				return nil
+			}
+			expr = ie
+			continue
+		}
 		fc := &ast.FunctionCalling{
 			Token: p.curToken,
 			Function: expr,
 		}
 		p.nextToken()
 		fc.Arguments = p.parseCommaSeparatedExpressions(token.RPAREN)
+		p.checkNamedArguments(fc.Arguments)
 		expr = fc
 	}
 	return expr
 }
 
 func (p *Parser) parseFunctionLiteral() ast.Expression {
-	// (){ ... }
-	// (a){ ... }
-	//  (a, b){ ... } のようなコードを想定(カンマは式の中には出てこないのと、関数リテラルのカッコ内は識別子だけなのを用いる)
-	if !(
-		p.curTokenIs(token.LPAREN) && p.peekTokenIs(token.RPAREN) && p.peek2TokenIs(token.LBRACE) ||
-		p.curTokenIs(token.LPAREN) && p.peekTokenIs(token.IDENT) && p.peek2TokenIs(token.RPAREN) && p.peek3TokenIs(token.LBRACE) ||
-		p.curTokenIs(token.LPAREN) && p.peekTokenIs(token.IDENT) && p.peek2TokenIs(token.COMMA) ) {
+	// x => x + 1
+	if p.curTokenIs(token.IDENT) && p.peekTokenIs(token.ARROW) && p.position+1 != p.guardEnd {
+		fl := &ast.FunctionLiteral{
+			Token: p.curToken,
+			Arguments: []ast.Identifier{*p.parseIdentifier()},
+			Defaults: []ast.Expression{nil},
+		}
+		p.nextToken()
+		return p.parseArrowBody(fl)
+	}
+
+	// (a, b){ ... } と (a, b) => a + b
+	// 対応する閉じカッコのすぐ後ろが { か => なら関数リテラル
+	if !p.curTokenIs(token.LPAREN) || !p.isFunctionLiteral() {
 		return p.parseParenthesisExpression()
 	}
 
-	token := p.curToken
+	fl := &ast.FunctionLiteral{Token: p.curToken}
 	p.nextToken()
-	args := p.parseCommaSeparatedIdentifiers()
+	fl.Arguments, fl.Defaults, fl.Patterns, fl.Rest = p.parseCommaSeparatedIdentifiers()
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		fl.ReturnType = p.parseTypeName()
+	}
+	if p.peekTokenIs(token.ARROW) {
+		p.nextToken()
+		return p.parseArrowBody(fl)
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
 	p.nextToken()
+	if !p.parseContracts(fl) {
+		return nil
+	}
+	fl.Body = p.parseStatements()
+	return fl
+}
+
+// 本体の先頭の requires 条件 と ensures 条件 を、改行で区切って並べる
+func (p *Parser) parseContracts(fl *ast.FunctionLiteral) bool {
+	for {
+		p.skipNewlines()
+		if !p.curTokenIs(token.REQUIRES) && !p.curTokenIs(token.ENSURES) {
+			return true
+		}
+		clause := p.curToken
+		p.nextToken()
+		condition := p.parseExpression()
+		if condition == nil {
+			p.appendError(fmt.Sprintf("expected condition after '%s'", clause.Literal))
+			return false
+		}
+		if clause.Type == token.REQUIRES {
+			fl.Requires = append(fl.Requires, condition)
+		} else {
+			fl.Ensures = append(fl.Ensures, condition)
+		}
+		p.nextToken()
+	}
+}
+
+// curTokenが => のときに、その後ろの式を本体として読む
+func (p *Parser) parseArrowBody(fl *ast.FunctionLiteral) ast.Expression {
 	p.nextToken()
-	stmts := p.parseStatements()
-	return &ast.FunctionLiteral{Token: token, Arguments: args, Body: stmts}
+	body := p.parseExpression()
+	if body == nil {
+		p.appendError("expected expression after '=>'")
+		return nil
+	}
+	fl.Body = []ast.Statement{&ast.ExpressionStatement{Expression: body}}
+	return fl
+}
+
+// curTokenの ( に対応する ) を探して、そのすぐ後ろを見る
+func (p *Parser) isFunctionLiteral() bool {
+	end := p.findClosing(p.position)
+	if end == -1 {
+		return false
+	}
+	next := end+1
+	// (n: int): int { ... } のように戻り値の型が付くこともある
+	if p.tokenAt(next).Type == token.COLON && p.tokenAt(end+2).Type == token.IDENT {
+		next = end+3
+	}
+	// n if (n > 3) => ... の => はガードの終わり
+	if p.tokenAt(next).Type == token.ARROW {
+		return next != p.guardEnd
+	}
+	return p.tokenAt(next).Type == token.LBRACE
+}
+
+// curTokenの型の名前を読む
+func (p *Parser) parseTypeName() *ast.TypeName {
+	if !p.curTokenIs(token.IDENT) {
+		p.appendError(fmt.Sprintf("expected type name, got '%s' instead", p.curToken.Literal))
+		return nil
+	}
+	return &ast.TypeName{Token: p.curToken, Name: p.curToken.Literal}
 }
 
 func (p *Parser) parseParenthesisExpression() ast.Expression {
@@ -226,8 +557,23 @@ func (p *Parser) parseLiteralAndIdentify() ast.Expression {
 	switch p.curToken.Type {
 	case token.LBRACK:
 		return p.parseArrayLiteral()
+	case token.LBRACE:
+		return p.parseHashLiteral()
+	case token.MATCH:
+		return p.parseMatchExpression()
+	case token.STRUCT:
+		return p.parseStructLiteral()
+	// 読めなかったときに型付きのnilを返さないよう、nilはそのまま返す
 	case token.INT:
-		return p.parseIntegerLiteral()
+		if lit := p.parseIntegerLiteral(); lit != nil {
+			return lit
+		}
+		return nil
+	case token.FLOAT:
+		if lit := p.parseFloatLiteral(); lit != nil {
+			return lit
+		}
+		return nil
 	case token.STRING:
 		return p.parseStringLiteral()
 	case token.IDENT:
@@ -237,6 +583,97 @@ func (p *Parser) parseLiteralAndIdentify() ast.Expression {
 	}
 }
 
+// struct(x, y) のフィールドは名前だけを並べる
+func (p *Parser) parseStructLiteral() ast.Expression {
+	sl := &ast.StructLiteral{Token: p.curToken}
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	fields, ok := p.parseFieldNames("struct")
+	if !ok {
+		return nil
+	}
+	sl.Fields = fields
+	return sl
+}
+
+// curTokenが ( のとき、) までのフィールドの名前を読む
+func (p *Parser) parseFieldNames(where string) ([]ast.Identifier, bool) {
+	fields := []ast.Identifier{ }
+	p.nextToken()
+	p.skipNewlines()
+	seen := map[string]bool{ }
+	for !p.curTokenIs(token.RPAREN) {
+		if !p.curTokenIs(token.IDENT) {
+			p.appendError(fmt.Sprintf("expected field name in %s, got '%s' instead", where, p.curToken.Literal))
+			return nil, false
+		}
+		field := p.parseIdentifier()
+		if seen[field.Name] {
+			p.appendError(fmt.Sprintf("duplicate field %s in %s", field.Name, where))
+		}
+		seen[field.Name] = true
+		fields = append(fields, *field)
+		p.nextToken()
+		p.skipNewlines()
+		if p.curTokenIs(token.COMMA) {
+			p.nextToken()
+			p.skipNewlines()
+		} else if !p.curTokenIs(token.RPAREN) {
+			p.appendError(fmt.Sprintf("expected ',' or ')' in %s, got '%s' instead", where, p.curToken.Type))
+			return nil, false
+		}
+	}
+	return fields, true
+}
+
+// enum Shape { Circle(r), Rect(w, h), Empty }
+// 値はカンマか改行で区切る
+func (p *Parser) parseEnumStatement() ast.Statement {
+	es := &ast.EnumStatement{Token: p.curToken}
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	es.Name = *p.parseIdentifier()
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	p.nextToken()
+	seen := map[string]bool{ }
+	for {
+		for p.curTokenIs(token.NEWLINE) || p.curTokenIs(token.COMMA) {
+			p.nextToken()
+		}
+		if p.curTokenIs(token.RBRACE) {
+			break
+		}
+		if !p.curTokenIs(token.IDENT) {
+			p.appendError(fmt.Sprintf("expected variant name in enum %s, got '%s' instead", es.Name.Name, p.curToken.Literal))
+			return nil
+		}
+		variant := ast.EnumVariant{Name: *p.parseIdentifier()}
+		if seen[variant.Name.Name] {
+			p.appendError(fmt.Sprintf("duplicate variant %s in enum %s", variant.Name.Name, es.Name.Name))
+		}
+		seen[variant.Name.Name] = true
+		if p.peekTokenIs(token.LPAREN) {
+			p.nextToken()
+			fields, ok := p.parseFieldNames(variant.Name.Name)
+			if !ok {
+				return nil
+			}
+			variant.Fields = fields
+		}
+		es.Variants = append(es.Variants, variant)
+		p.nextToken()
+		if !p.curTokenIs(token.NEWLINE) && !p.curTokenIs(token.COMMA) && !p.curTokenIs(token.RBRACE) {
+			p.appendError(fmt.Sprintf("expected ',' or newline after variant in enum %s, got '%s' instead", es.Name.Name, p.curToken.Type))
+			return nil
+		}
+	}
+	return es
+}
+
 func (p *Parser) parseArrayLiteral() *ast.ArrayLiteral {
 	tok := p.curToken
 	p.nextToken()
@@ -244,25 +681,121 @@ func (p *Parser) parseArrayLiteral() *ast.ArrayLiteral {
 	return &ast.ArrayLiteral{Token: tok, Value: list}
 }
 
-func (p *Parser) parseCommaSeparatedIdentifiers() []ast.Identifier {
-	var list []ast.Identifier
-	if !p.curTokenIs(token.IDENT){
-		empty := []ast.Identifier { }
-		return empty
+// {"a": 1, "b": 2} のように、キーと値をコロンでつなげて並べる
+// 中括弧の中では改行してもよい。読み終わるとcurTokenは閉じ括弧になる
+// 読めなかったときは、型付きのnilにならないようast.Expressionのnilを返す
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: p.curToken, Keys: []ast.Expression{ }, Values: []ast.Expression{ }}
+	p.nextToken()
+	p.skipNewlines()
+	for !p.curTokenIs(token.RBRACE) {
+		if p.curTokenIs(token.EOF) {
+			p.appendError("expected '}' at the end of hash literal")
+			return nil
+		}
+		key := p.parseExpression()
+		if key == nil {
+			p.appendError(fmt.Sprintf("unexpected '%s' in hash literal", p.curToken.Literal))
+			return nil
+		}
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+		p.nextToken()
+		p.skipNewlines()
+		value := p.parseExpression()
+		if value == nil {
+			p.appendError(fmt.Sprintf("unexpected '%s' in hash literal", p.curToken.Literal))
+			return nil
+		}
+		hash.Keys = append(hash.Keys, key)
+		hash.Values = append(hash.Values, value)
+		p.nextToken()
+		p.skipNewlines()
+		if p.curTokenIs(token.COMMA) {
+			p.nextToken()
+			p.skipNewlines()
+		} else if !p.curTokenIs(token.RBRACE) {
+			p.appendError(fmt.Sprintf("expected ',' or '}' in hash literal, got '%s' instead", p.curToken.Literal))
+			return nil
+		}
 	}
-	for p.curTokenIs(token.IDENT) {
-		ident := p.parseIdentifier()
-		if ident == nil {
-			msg := fmt.Sprintf("could is not parse %q as identifier", p.curToken.Literal)
+	return hash
+}
+
+// 関数リテラルの引数を読む。読み終わるとcurTokenは閉じカッコになる
+// 初期値のある引数のあとに初期値のない引数は置けず、...restは最後にしか置けない
+// [a, b] や {"k": v} と書いた引数は分割して受け取る
+func (p *Parser) parseCommaSeparatedIdentifiers() ([]ast.Identifier, []ast.Expression, []ast.Pattern, *ast.Identifier) {
+	list := []ast.Identifier { }
+	var defaults []ast.Expression
+	var patterns []ast.Pattern
+	var rest *ast.Identifier
+	hasDefault := false
+	p.skipNewlines()
+	for !p.curTokenIs(token.RPAREN) {
+		if rest != nil {
+			p.appendError(fmt.Sprintf("rest parameter ...%s must be the last parameter", rest.Name))
+			return list, defaults, patterns, rest
+		}
+		isRest := p.curTokenIs(token.ELLIPSIS)
+		if isRest {
+			p.nextToken()
+		}
+		var ident *ast.Identifier
+		var pattern ast.Pattern
+		switch {
+		case !isRest && (p.curTokenIs(token.LBRACK) || p.curTokenIs(token.LBRACE)):
+			tok := p.curToken
+			pattern = p.parsePattern()
+			if pattern == nil {
+				return list, defaults, patterns, rest
+			}
+			ident = &ast.Identifier{Token: tok, Name: pattern.String()}
+		case p.curTokenIs(token.IDENT):
+			ident = p.parseIdentifier()
+		default:
+			msg := fmt.Sprintf("could not parse %q as identifier", p.curToken.Literal)
 			p.appendError(msg)
+			return list, defaults, patterns, rest
 		}
 		p.nextToken()
+		if p.curTokenIs(token.COLON) {
+			p.nextToken()
+			ident.Type = p.parseTypeName()
+			if ident.Type == nil {
+				return list, defaults, patterns, rest
+			}
+			p.nextToken()
+		}
+		switch {
+		case isRest:
+			rest = ident
+		case p.curTokenIs(token.ASSIGN):
+			p.nextToken()
+			list = append(list, *ident)
+			defaults = append(defaults, p.parseExpression())
+			patterns = append(patterns, pattern)
+			hasDefault = true
+			p.nextToken()
+		default:
+			if hasDefault {
+				p.appendError(fmt.Sprintf("parameter %s without default value follows parameters with default values", ident.Name))
+			}
+			list = append(list, *ident)
+			defaults = append(defaults, nil)
+			patterns = append(patterns, pattern)
+		}
+		p.skipNewlines()
 		if p.curTokenIs(token.COMMA) {
 			p.nextToken()
+			p.skipNewlines()
+		} else if !p.curTokenIs(token.RPAREN) {
+			p.appendError(fmt.Sprintf("expected ',' or ')' in parameters, got '%s' instead", p.curToken.Type))
+			return list, defaults, patterns, rest
 		}
-		list = append(list, *ident)
 	}
-	return list
+	return list, defaults, patterns, rest
 }
 
 func (p *Parser) parseCommaSeparatedExpressions(endToken token.TokenType) []ast.Expression {
@@ -272,11 +805,18 @@ func (p *Parser) parseCommaSeparatedExpressions(endToken token.TokenType) []ast.
 		return empty
 	}
 	for {
-		expr := p.parseExpression()
-		p.nextToken()
+		var expr ast.Expression
+		// 関数呼び出しのときだけ f(x: 1) のように名前付きで渡せる
+		if endToken == token.RPAREN && p.curTokenIs(token.IDENT) && p.peekTokenIs(token.COLON) {
+			expr = p.parseNamedArgument()
+		} else {
+			expr = p.parseExpression()
+		}
 		if expr==nil {
+			p.appendError(fmt.Sprintf("expected expression or '%s', got '%s' instead", endToken, p.curToken.Type))
 			break
 		}
+		p.nextToken()
 		list = append(list, expr)
 		// , ...
 		// ]
@@ -287,7 +827,6 @@ func (p *Parser) parseCommaSeparatedExpressions(endToken token.TokenType) []ast.
 		if p.curTokenIs(token.COMMA) {
 			p.nextToken()
 			if p.curTokenIs(endToken) {
-				p.nextToken()
 				break
 			}
 		}
@@ -295,12 +834,79 @@ func (p *Parser) parseCommaSeparatedExpressions(endToken token.TokenType) []ast.
 	return list
 }
 
+func (p *Parser) parseNamedArgument() ast.Expression {
+	na := &ast.NamedArgument{Token: p.curToken, Name: *p.parseIdentifier()}
+	p.nextToken()
+	p.nextToken()
+	na.Value = p.parseExpression()
+	if na.Value == nil {
+		return nil
+	}
+	return na
+}
+
+// 名前付き引数は位置で渡す引数より後ろに置き、同じ名前は一度しか使えない
+func (p *Parser) checkNamedArguments(args []ast.Expression) {
+	names := map[string]bool{ }
+	for _, arg := range args {
+		na, ok := arg.(*ast.NamedArgument)
+		if !ok {
+			if len(names) != 0 {
+				p.appendError(fmt.Sprintf("positional argument %s follows keyword arguments", arg.String()))
+			}
+			continue
+		}
+		if names[na.Name.Name] {
+			p.appendError(fmt.Sprintf("duplicate keyword argument %s", na.Name.Name))
+		}
+		names[na.Name.Name] = true
+	}
+}
+
+// 0x, 0o, 0bの接頭辞があればその基数で読む
+// int("010")と同じく、0から始まるだけの数は8進数ではなく10進数として扱う
 func (p *Parser) parseIntegerLiteral() *ast.IntegerLiteral {
 	lit := &ast.IntegerLiteral{Token: p.curToken}
 
-	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	digits := p.curToken.Literal
+	base := 0
+	if !hasRadixPrefix(digits) {
+		// 基数を指定すると_を受け付けないので、先に取り除く。_の位置は字句解析で調べてある
+		base = 10
+		digits = strings.ReplaceAll(digits, "_", "")
+	}
+	value, err := strconv.ParseInt(digits, base, 64)
+	if err == nil {
+		lit.Value = value
+		return lit
+	}
+	bigValue, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
+		p.appendError(msg)
+		return nil
+	}
+	lit.BigValue = bigValue
+	return lit
+}
+
+func hasRadixPrefix(literal string) bool {
+	if len(literal) < 2 || literal[0] != '0' {
+		return false
+	}
+	switch literal[1] {
+	case 'x', 'X', 'o', 'O', 'b', 'B':
+		return true
+	}
+	return false
+}
+
+func (p *Parser) parseFloatLiteral() *ast.FloatLiteral {
+	lit := &ast.FloatLiteral{Token: p.curToken}
+
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
 	if err != nil {
-		msg := fmt.Sprintf("could is not parse %q as integer", p.curToken.Literal)
+		msg := fmt.Sprintf("could not parse %q as float", p.curToken.Literal)
 		p.appendError(msg)
 		return nil
 	}
@@ -330,12 +936,11 @@ func (p *Parser) peekTokenIs(t token.TokenType) bool {
 	return p.peekToken.Type == t
 }
 
-func (p *Parser) peek2TokenIs(t token.TokenType) bool {
-	return p.peek2Token.Type == t
-}
-
-func (p *Parser) peek3TokenIs(t token.TokenType) bool {
-	return p.peek3Token.Type == t
+// 引数の並びの中の改行は読み飛ばす
+func (p *Parser) skipNewlines() {
+	for p.curTokenIs(token.NEWLINE) {
+		p.nextToken()
+	}
 }
 
 func (p *Parser) expectPeek(t token.TokenType) bool {
@@ -3,6 +3,7 @@ package parser
 import (
 	"testing"
 	"fmt"
+	"strings"
 	"yokan/ast"
 	"yokan/lexer"
 )
@@ -118,6 +119,13 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 		{"a + -b - c", "((a + (-b)) - c)"},
 		{"a * b * c", "((a * b) * c)"},
 		{"a * +b / c", "((a * (+b)) / c)"},
+		{"a + b % c * d", "(a + ((b % c) * d))"},
+		{"a[0] + b[1][2]", "((a[0]) + ((b[1])[2]))"},
+		{"-a[i + 1]", "(-(a[(i + 1)]))"},
+		{"f(x)[0](y)", "(f(x)[0])(y)"},
+		{"p.x + q.y * 2", "(p.x + (q.y * 2))"},
+		{"a.b.c(1).d[0]", "(a.b.c(1).d[0])"},
+		{"-p.x", "(-p.x)"},
 		{"a + b * c + d / e - f", "(((a + (b * c)) + (d / e)) - f)"},
 		{"5 > 4 == 3 < 4", "((5 > 4) == (3 < 4))"},
 		{"5 <= 4 != 3 >= 4", "((5 <= 4) != (3 >= 4))"},
@@ -201,6 +209,44 @@ func TestFunctionCalling2(t *testing.T) {
 	checkIdentifier(t, innerCalling.Function, "func")
 }
 
+func TestNamedArguments(t *testing.T) {
+	input := "f(1, y: 2, z: a + b)"
+
+	expr := checkCommonTestsAndParseExpression(t, input)
+
+	call, ok := expr.(*ast.FunctionCalling)
+	if !ok {
+		t.Fatalf("expr is not *ast.FunctionCalling. got=%T", expr)
+	}
+	if len(call.Arguments) != 3 {
+		t.Fatalf("len(call.Arguments) is not 3. got=%d", len(call.Arguments))
+	}
+	checkIntegerLiteral(t, call.Arguments[0], 1)
+	named, ok := call.Arguments[1].(*ast.NamedArgument)
+	if !ok {
+		t.Fatalf("call.Arguments[1] is not *ast.NamedArgument. got=%T", call.Arguments[1])
+	}
+	checkIdentifier(t, &named.Name, "y")
+	checkIntegerLiteral(t, named.Value, 2)
+	if call.Arguments[2].String() != "z: (a + b)" {
+		t.Errorf("call.Arguments[2] is not z: (a + b). got=%s", call.Arguments[2])
+	}
+}
+
+func TestNamedArgumentsError(t *testing.T) {
+	tests := []string {
+		"f(x: 1, 2)",
+		"f(x: 1, x: 2)",
+	}
+	for _, input := range tests {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("%s: parser has no errors", input)
+		}
+	}
+}
+
 func TestFunctionLiteral(t *testing.T) {
 	input := "(aa,bb,){\ncc=44\nee\n}"
 
@@ -229,6 +275,545 @@ func TestFunctionLiteral(t *testing.T) {
 	checkIdentifier(t, second_stmt.Expression, "ee")
 }
 
+func TestFunctionLiteralWithDefaultsAndRest(t *testing.T) {
+	input := "(a, b = 10, c = a + 1, ...rest){ a }"
+
+	expr := checkCommonTestsAndParseExpression(t, input)
+
+	fun, ok := expr.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("expr is not *ast.FunctionLiteral. got=%T", expr)
+	}
+	if len(fun.Arguments) != 3 || len(fun.Defaults) != 3 {
+		t.Fatalf("function does not have 3 arguments. got=%d arguments, %d defaults", len(fun.Arguments), len(fun.Defaults))
+	}
+	checkIdentifier(t, &fun.Arguments[0], "a")
+	checkIdentifier(t, &fun.Arguments[1], "b")
+	checkIdentifier(t, &fun.Arguments[2], "c")
+	if fun.Defaults[0] != nil {
+		t.Errorf("fun.Defaults[0] is not nil. got=%s", fun.Defaults[0])
+	}
+	checkIntegerLiteral(t, fun.Defaults[1], 10)
+	if fun.Defaults[2].String() != "(a + 1)" {
+		t.Errorf("fun.Defaults[2] is not (a + 1). got=%s", fun.Defaults[2])
+	}
+	if fun.Rest == nil {
+		t.Fatalf("fun.Rest is nil")
+	}
+	checkIdentifier(t, fun.Rest, "rest")
+
+	tests := []testInString {
+		{"(...xs){ xs }", "(...xs) {\n\txs\n}"},
+		{"(a = 1){ a }", "(a = 1) {\n\ta\n}"},
+	}
+	checkExpressionsInString(t, tests)
+}
+
+func TestArrowFunctionLiteral(t *testing.T) {
+	tests := []testInString {
+		{"x => x + 1", "(x) {\n\t(x + 1)\n}"},
+		{"(a, b) => a + b", "(a, b) {\n\t(a + b)\n}"},
+		{"() => 1", "() {\n\t1\n}"},
+		{"(a, b = 2, ...c) => a", "(a, b = 2, ...c) {\n\ta\n}"},
+		{"map(xs, x => x * 2)", "map(xs, (x) {\n\t(x * 2)\n})"},
+		{"x => y => x + y", "(x) {\n\t(y) {\n\t\t(x + y)\n\t}\n}"},
+		{"(x) => {\"a\": x}", "(x) {\n\t{\"a\": x}\n}"},
+		{"(a + b)", "(a + b)"},
+		{"(f(a) + [b])", "(f(a) + [b])"},
+	}
+	checkExpressionsInString(t, tests)
+}
+
+func TestPipeExpression(t *testing.T) {
+	tests := []testInString {
+		{"x |> f", "f(x)"},
+		{"x |> f(a)", "f(x, a)"},
+		{"x |> f(a) |> g", "g(f(x, a))"},
+		{"x |> f(a)(b)", "f(a)(x, b)"},
+		{"1 + 2 |> f", "f((1 + 2))"},
+		{"a == b |> f", "f((a == b))"},
+		{"x |> f(y: 1)", "f(x, y: 1)"},
+		{"x\n  |> f\n\n  |> g(1)", "g(f(x), 1)"},
+		{"x |> f(a, )", "f(x, a)"},
+	}
+	checkExpressionsInString(t, tests)
+
+	program := checkCommonTestsAndParse(t, "x\nf", 2)
+	if program.Statements[0].String() != "x" {
+		t.Errorf("newline without pipe does not end the statement. got=%s", program.Statements[0])
+	}
+}
+
+func TestPipeExpressionError(t *testing.T) {
+	tests := []string {
+		"|> f",
+		"x = |> f",
+		"x |>",
+		"x |> )",
+		"x |> f(",
+		"x |> [",
+		"x |> f(,)",
+	}
+	for _, input := range tests {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("%s: parser has no errors", input)
+		}
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	input := `match (x) {
+		0 => "zero", -1.5 => "minus"
+		"a" => 1
+		true => 2
+		[a, [b], ...rest] => a
+		[..._] => 3
+		{"k": v, 1: _} => v
+		n if n > y => n
+		m if (m > y) => m
+		k if even(k) => k
+		_ => null
+	}`
+
+	expr := checkCommonTestsAndParseExpression(t, input)
+
+	me, ok := expr.(*ast.MatchExpression)
+	if !ok {
+		t.Fatalf("expr is not *ast.MatchExpression. got=%T", expr)
+	}
+	checkIdentifier(t, me.Value, "x")
+	expected := []struct {
+		pattern string
+		patternType string
+		guard string
+		body string
+	} {
+		{"0", "*ast.LiteralPattern", "", `"zero"`},
+		{"(-1.5)", "*ast.LiteralPattern", "", `"minus"`},
+		{`"a"`, "*ast.LiteralPattern", "", "1"},
+		{"true", "*ast.LiteralPattern", "", "2"},
+		{"[a, [b], ...rest]", "*ast.ArrayPattern", "", "a"},
+		{"[..._]", "*ast.ArrayPattern", "", "3"},
+		{`{"k": v, 1: _}`, "*ast.HashPattern", "", "v"},
+		{"n", "*ast.BindingPattern", "(n > y)", "n"},
+		{"m", "*ast.BindingPattern", "(m > y)", "m"},
+		{"k", "*ast.BindingPattern", "even(k)", "k"},
+		{"_", "*ast.WildcardPattern", "", "null"},
+	}
+	if len(me.Arms) != len(expected) {
+		t.Fatalf("len(me.Arms) is not %d. got=%d", len(expected), len(me.Arms))
+	}
+	for i, tt := range expected {
+		arm := me.Arms[i]
+		if arm.Pattern.String() != tt.pattern {
+			t.Errorf("arms[%d].Pattern is not %s. got=%s", i, tt.pattern, arm.Pattern)
+		}
+		if fmt.Sprintf("%T", arm.Pattern) != tt.patternType {
+			t.Errorf("arms[%d].Pattern is not %s. got=%T", i, tt.patternType, arm.Pattern)
+		}
+		guard := ""
+		if arm.Guard != nil {
+			guard = arm.Guard.String()
+		}
+		if guard != tt.guard {
+			t.Errorf("arms[%d].Guard is not %q. got=%q", i, tt.guard, guard)
+		}
+		if arm.Body.String() != tt.body {
+			t.Errorf("arms[%d].Body is not %s. got=%s", i, tt.body, arm.Body)
+		}
+	}
+}
+
+func TestMatchExpressionError(t *testing.T) {
+	tests := []string {
+		"match x { _ => 1 }",
+		"match (x) { 1 2 }",
+		"match (x) { [a, a] => 1 }",
+		"match (x) { [...a, b] => 1 }",
+		"match (x) { f(1) => 1 }",
+		"match (x) { 1 => 1 2 => 2 }",
+		"match (x) { _ => 1",
+	}
+	for _, input := range tests {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("%s: parser has no errors", input)
+		}
+	}
+}
+
+func TestDestructuringAssign(t *testing.T) {
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{"[a, b] = pair", "[a, b] = pair\n"},
+		{"[head, ...tail] = xs", "[head, ...tail] = xs\n"},
+		{`{"name": n} = record`, `{"name": n} = record` + "\n"},
+		{"[a, [b, _]] = f(1)", "[a, [b, _]] = f(1)\n"},
+	}
+	for _, tt := range tests {
+		program := checkCommonTestsAndParse(t, tt.input, 1)
+		da, ok := program.Statements[0].(*ast.DestructuringAssign)
+		if !ok {
+			t.Fatalf("%s: program.Statements[0] is not *ast.DestructuringAssign. got=%T", tt.input, program.Statements[0])
+		}
+		if da.String() != tt.expected {
+			t.Errorf("%s: da.String() is not %q. got=%q", tt.input, tt.expected, da.String())
+		}
+	}
+
+	// = が続かなければ配列とハッシュのまま
+	for _, input := range []string{"[a, b]", `{"a": 1}`, "[a] == [b]"} {
+		expr := checkCommonTestsAndParseExpression(t, input)
+		if expr.String() != input && expr.String() != "("+input+")" {
+			t.Errorf("%s: expr.String() is %s", input, expr.String())
+		}
+	}
+
+	errors := []string {
+		"[a, a] = pair",
+		"[...t, h] = xs",
+		"[a + 1] = xs",
+		"[a] =",
+	}
+	for _, input := range errors {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("%s: parser has no errors", input)
+		}
+	}
+}
+
+func TestDestructuringParameters(t *testing.T) {
+	expr := checkCommonTestsAndParseExpression(t, `([a, b], c, {"k": v} = {"k": 1}){ a }`)
+	fl, ok := expr.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("expr is not *ast.FunctionLiteral. got=%T", expr)
+	}
+	expected := []string{"[a, b]", "c", `{"k": v} = {"k": 1}`}
+	params := ast.ParameterStrings(fl.Arguments, fl.Defaults, fl.Rest)
+	if strings.Join(params, ", ") != strings.Join(expected, ", ") {
+		t.Errorf("params is not %q. got=%q", expected, params)
+	}
+	if fl.Patterns[0] == nil || fl.Patterns[1] != nil || fl.Patterns[2] == nil {
+		t.Errorf("fl.Patterns is wrong. got=%v", fl.Patterns)
+	}
+}
+
+func TestIndexAndCompoundAssign(t *testing.T) {
+	tests := []struct {
+		input string
+		expectedType string
+		expected string
+	} {
+		{"arr[0] = 1", "*ast.IndexAssign", "arr[0] = 1\n"},
+		{`h["k"] = v + 1`, "*ast.IndexAssign", `h["k"] = (v + 1)` + "\n"},
+		{"m[i][j] = 0", "*ast.IndexAssign", "(m[i])[j] = 0\n"},
+		{"x += 1", "*ast.CompoundAssign", "x += 1\n"},
+		{"x -= 1", "*ast.CompoundAssign", "x -= 1\n"},
+		{"x *= 2", "*ast.CompoundAssign", "x *= 2\n"},
+		{"x /= 2", "*ast.CompoundAssign", "x /= 2\n"},
+		{"x %= 2", "*ast.CompoundAssign", "x %= 2\n"},
+		{"arr[i] += a * b", "*ast.CompoundAssign", "arr[i] += (a * b)\n"},
+		{"p.x = 1", "*ast.MemberAssign", "p.x = 1\n"},
+		{"Point.norm = () => self.x", "*ast.MemberAssign", "Point.norm = () {\n\tself.x\n}\n"},
+		{"a[0].x = b.y", "*ast.MemberAssign", "(a[0]).x = b.y\n"},
+		{"p.x -= 1", "*ast.CompoundAssign", "p.x -= 1\n"},
+	}
+	for _, tt := range tests {
+		program := checkCommonTestsAndParse(t, tt.input, 1)
+		stmt := program.Statements[0]
+		if fmt.Sprintf("%T", stmt) != tt.expectedType {
+			t.Errorf("%s: stmt is not %s. got=%T", tt.input, tt.expectedType, stmt)
+			continue
+		}
+		if stmt.String() != tt.expected {
+			t.Errorf("%s: stmt.String() is not %q. got=%q", tt.input, tt.expected, stmt.String())
+		}
+	}
+
+	errors := []string {
+		"f(x) = 1",
+		"p.f() = 1",
+		"p.x =",
+		"1 += 1",
+		"a + b = 1",
+		"arr[0] =",
+		"x +=",
+		"arr[] = 1",
+	}
+	for _, input := range errors {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("%s: parser has no errors", input)
+		}
+	}
+}
+
+func TestStructLiteral(t *testing.T) {
+	program := checkCommonTestsAndParse(t, "Point = struct(x, y)\n struct(\n a\n)", 2)
+	assign, ok := program.Statements[0].(*ast.Assign)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not *ast.Assign. got=%T", program.Statements[0])
+	}
+	sl, ok := assign.Value.(*ast.StructLiteral)
+	if !ok {
+		t.Fatalf("assign.Value is not *ast.StructLiteral. got=%T", assign.Value)
+	}
+	if sl.Name != "Point" || sl.String() != "struct(x, y)" {
+		t.Errorf("sl is not Point = struct(x, y). got=%s = %s", sl.Name, sl.String())
+	}
+	anonymous := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.StructLiteral)
+	if anonymous.Name != "" || anonymous.String() != "struct(a)" {
+		t.Errorf("anonymous is not struct(a). got=%q %s", anonymous.Name, anonymous.String())
+	}
+
+	errors := []string {
+		"struct x, y",
+		"struct(x, x)",
+		"struct(1)",
+		"struct(x y)",
+		"p.1",
+		"p.",
+	}
+	for _, input := range errors {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("%s: parser has no errors", input)
+		}
+	}
+}
+
+func TestEnumStatement(t *testing.T) {
+	input := `enum Shape {
+		Circle(r),
+		Rect(w, h)
+		Empty
+	}`
+	program := checkCommonTestsAndParse(t, input, 1)
+	es, ok := program.Statements[0].(*ast.EnumStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not *ast.EnumStatement. got=%T", program.Statements[0])
+	}
+	expected := "enum Shape { Circle(r), Rect(w, h), Empty }\n"
+	if es.String() != expected {
+		t.Errorf("es.String() is not %q. got=%q", expected, es.String())
+	}
+	if es.Variants[2].Fields != nil {
+		t.Errorf("Empty has fields. got=%v", es.Variants[2].Fields)
+	}
+
+	errors := []string {
+		"enum { A }",
+		"enum Shape A",
+		"enum Shape { A, A }",
+		"enum Shape { A(x, x) }",
+		"enum Shape { A(1) }",
+		"enum Shape { A B }",
+		"enum Shape { 1 }",
+	}
+	for _, input := range errors {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("%s: parser has no errors", input)
+		}
+	}
+}
+
+func TestConstructorPattern(t *testing.T) {
+	input := `match (s) {
+		Circle(r) => r
+		Rect(w, [h, _]) => w
+		Empty => 0
+		Unit() => 1
+		empty => 2
+	}`
+	expr := checkCommonTestsAndParseExpression(t, input)
+	me := expr.(*ast.MatchExpression)
+	expected := []struct {
+		pattern string
+		patternType string
+	} {
+		{"Circle(r)", "*ast.ConstructorPattern"},
+		{"Rect(w, [h, _])", "*ast.ConstructorPattern"},
+		{"Empty", "*ast.BindingPattern"},
+		{"Unit()", "*ast.ConstructorPattern"},
+		{"empty", "*ast.BindingPattern"},
+	}
+	for i, tt := range expected {
+		pattern := me.Arms[i].Pattern
+		if pattern.String() != tt.pattern || fmt.Sprintf("%T", pattern) != tt.patternType {
+			t.Errorf("arms[%d].Pattern is not %s(%s). got=%s(%T)", i, tt.pattern, tt.patternType, pattern, pattern)
+		}
+	}
+
+	errors := []string {
+		"match (s) { Rect(w, w) => 1 }",
+		"match (s) { Rect(w h) => 1 }",
+		"match (s) { Rect(1 + 2) => 1 }",
+	}
+	for _, input := range errors {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("%s: parser has no errors", input)
+		}
+	}
+}
+
+func TestTypeAnnotation(t *testing.T) {
+	tests := []struct {
+		input string
+		expected string
+	} {
+		{"x: int = 1", "x: int = 1\n"},
+		{"f = (n: int, s: string = \"a\", ...r: array): string { s }", "f = (n: int, s: string = \"a\", ...r: array): string {\n\ts\n}\n"},
+		{"g = (a: float): float => a", "g = (a: float): float {\n\ta\n}\n"},
+		{"h = ([a, b]: array) { a }", "h = ([a, b]: array) {\n\ta\n}\n"},
+	}
+	for _, tt := range tests {
+		program := checkCommonTestsAndParse(t, tt.input, 1)
+		if program.String() != tt.expected {
+			t.Errorf("%s: program.String() is not %q. got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+
+	assign := checkCommonTestsAndParse(t, "x: int = 1", 1).Statements[0].(*ast.Assign)
+	if assign.Name.Type == nil || assign.Name.Type.Name != "int" {
+		t.Errorf("assign.Name.Type is not int. got=%v", assign.Name.Type)
+	}
+	fun := checkCommonTestsAndParseExpression(t, "(a, b: int): bool { a }").(*ast.FunctionLiteral)
+	if fun.Arguments[0].Type != nil || fun.Arguments[1].Type.Name != "int" || fun.ReturnType.Name != "bool" {
+		t.Errorf("types of %s are wrong", fun.String())
+	}
+
+	errors := []string {
+		"x: = 1",
+		"x: 1 = 1",
+		"f = (a: ) { a }",
+		"f = (a): { a }",
+	}
+	for _, input := range errors {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("%s: parser has no errors", input)
+		}
+	}
+
+	// 右辺がないときは、評価まで進まずに構文エラーになる
+	missing := []struct {
+		input string
+		expected string
+	} {
+		{"x: int =", "expected expression after 'x: int ='"},
+		{"x: int = )", "expected expression after 'x: int ='"},
+		{"x =", "expected expression after 'x ='"},
+	}
+	for _, tt := range missing {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 1 || p.Errors()[0] != tt.expected {
+			t.Errorf("%s: errors is not [%q]. got=%q", tt.input, tt.expected, p.Errors())
+		}
+		if len(program.Statements) != 0 {
+			t.Errorf("%s: program has statements. got=%q", tt.input, program.String())
+		}
+	}
+}
+
+func TestContracts(t *testing.T) {
+	input := `f = (n) {
+
+		requires n >= 0
+		requires n < 100
+		ensures result > n
+		n + 1
+	}`
+	program := checkCommonTestsAndParse(t, input, 1)
+	fl := program.Statements[0].(*ast.Assign).Value.(*ast.FunctionLiteral)
+	if len(fl.Requires) != 2 || len(fl.Ensures) != 1 || len(fl.Body) != 1 {
+		t.Fatalf("contracts of %s are wrong", fl.String())
+	}
+	expected := "f = (n) {\n\trequires (n >= 0)\n\trequires (n < 100)\n\tensures (result > n)\n\t(n + 1)\n}\n"
+	if program.String() != expected {
+		t.Errorf("program.String() is not %q. got=%q", expected, program.String())
+	}
+	if fl.Name != "f" {
+		t.Errorf("fl.Name is not f. got=%q", fl.Name)
+	}
+
+	errors := []string {
+		"requires x > 0",
+		"f = (n) { n\n requires n > 0 }",
+		"f = (n) { requires }",
+		"f = n => ensures n",
+	}
+	for _, input := range errors {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("%s: parser has no errors", input)
+		}
+	}
+}
+
+func TestFunctionLiteralLookahead(t *testing.T) {
+	tests := []struct {
+		input string
+		params int
+	} {
+		{"(a, b, c, d, e){ a }", 5},
+		{"(a,\n b,\n c\n){ a }", 3},
+		{"(\n  a = [1, (2)],\n  ...rest\n) => a", 1},
+		{"(a) {\n a\n}", 1},
+	}
+	for _, tt := range tests {
+		expr := checkCommonTestsAndParseExpression(t, tt.input)
+		fun, ok := expr.(*ast.FunctionLiteral)
+		if !ok {
+			t.Errorf("%q: expr is not *ast.FunctionLiteral. got=%T", tt.input, expr)
+			continue
+		}
+		if len(fun.Arguments) != tt.params {
+			t.Errorf("%q: len(fun.Arguments) is not %d. got=%d", tt.input, tt.params, len(fun.Arguments))
+		}
+	}
+}
+
+func TestFunctionLiteralParametersError(t *testing.T) {
+	tests := []string {
+		"(a = 1, b){ a }",
+		"(...a, b){ a }",
+		"(...a, ...b){ a }",
+		"(a = 1 2){ a }",
+		"(1, 2){ a }",
+		"(a) =>",
+	}
+	for _, input := range tests {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("%s: parser has no errors", input)
+		}
+	}
+
+	p := New(lexer.New("(1, 2){ a }"))
+	p.ParseProgram()
+	expected := `could not parse "1" as identifier`
+	if len(p.Errors()) == 0 || p.Errors()[0] != expected {
+		t.Errorf("errors is not [%q]. got=%q", expected, p.Errors())
+	}
+}
+
 func TestFunctionLiteralWithCalling(t *testing.T) {
 	input := "(){}()"
 
@@ -292,6 +877,104 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	checkIntegerLiteral(t, expr, 11)
 }
 
+func TestPrefixedIntegerLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input string
+		expected int64
+	} {
+		{"0x1F", 31},
+		{"0o17", 15},
+		{"0b101", 5},
+		{"1_000_000", 1000000},
+		{"010", 10},
+		{"09", 9},
+	}
+	for _, tt := range tests {
+		expr := checkCommonTestsAndParseExpression(t, tt.input)
+		integ, ok := expr.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("expr is not *ast.IntegerLiteral. got=%T", expr)
+		}
+		if integ.Value != tt.expected {
+			t.Errorf("integ.Value is not %d. got=%d", tt.expected, integ.Value)
+		}
+	}
+
+	expr := checkCommonTestsAndParseExpression(t, "0100_000_000_000_000_000_000")
+	integ, ok := expr.(*ast.IntegerLiteral)
+	if !ok {
+		t.Fatalf("expr is not *ast.IntegerLiteral. got=%T", expr)
+	}
+	if integ.BigValue == nil || integ.BigValue.String() != "100000000000000000000" {
+		t.Fatalf("integ.BigValue is not 100000000000000000000. got=%s", integ.BigValue)
+	}
+
+	expr = checkCommonTestsAndParseExpression(t, "0xFFFF_FFFF_FFFF_FFFF_FFFF")
+	integ, ok = expr.(*ast.IntegerLiteral)
+	if !ok {
+		t.Fatalf("expr is not *ast.IntegerLiteral. got=%T", expr)
+	}
+	if integ.BigValue == nil || integ.BigValue.String() != "1208925819614629174706175" {
+		t.Fatalf("integ.BigValue is not 1208925819614629174706175. got=%s", integ.BigValue)
+	}
+}
+
+func TestMalformedNumberLiteralError(t *testing.T) {
+	p := New(lexer.New("a = 0x\nb = 12ab"))
+	p.ParseProgram()
+	expected := []string {
+		`malformed number literal "0x": no digits after "0x"`,
+		`malformed number literal "12ab": unexpected character 'a'`,
+	}
+	errors := p.Errors()
+	if len(errors) != len(expected) {
+		t.Fatalf("len(errors) is not %d. got=%q", len(expected), errors)
+	}
+	for i, msg := range expected {
+		if errors[i] != msg {
+			t.Errorf("errors[%d] is not %q. got=%q", i, msg, errors[i])
+		}
+	}
+}
+
+func TestBigIntegerLiteralExpression(t *testing.T) {
+	input := "123456789012345678901234567890"
+
+	expr := checkCommonTestsAndParseExpression(t, input)
+
+	integ, ok := expr.(*ast.IntegerLiteral)
+	if !ok {
+		t.Fatalf("expr is not *ast.IntegerLiteral. got=%T", expr)
+	}
+	if integ.BigValue == nil || integ.BigValue.String() != input {
+		t.Fatalf("integ.BigValue is not %s. got=%s", input, integ.BigValue)
+	}
+}
+
+func TestFloatLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input string
+		expected float64
+	} {
+		{"3.14", 3.14},
+		{"1e-9", 1e-9},
+		{"2.5E+3", 2500},
+	}
+	for _, tt := range tests {
+		expr := checkCommonTestsAndParseExpression(t, tt.input)
+		lit, ok := expr.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("expr is not *ast.FloatLiteral. got=%T", expr)
+		}
+		if lit.Value != tt.expected {
+			t.Errorf("lit.Value is not %g. got=%g", tt.expected, lit.Value)
+		}
+		if lit.TokenLiteral() != tt.input {
+			t.Errorf("lit.TokenLiteral is not %s. got %s", tt.input, lit.TokenLiteral())
+		}
+	}
+}
+
 func TestStringLiteralExpression(t *testing.T) {
 	input := `"aa\n\t\"a"`
 
@@ -333,6 +1016,65 @@ func TestArrayLiteralExperession(t *testing.T) {
 	}
 }
 
+func TestHashLiteralExperession(t *testing.T) {
+	input := `{"a": 1, 2: "b", "c": {}}`
+
+	expr := checkCommonTestsAndParseExpression(t, input)
+
+	hash, ok := expr.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("hash is not *ast.HashLiteral. got=%T", expr)
+	}
+	if len(hash.Keys) != 3 || len(hash.Values) != 3 {
+		t.Fatalf("hash does not have 3 pairs. got=%d keys, %d values", len(hash.Keys), len(hash.Values))
+	}
+	checkStringLiteral(t, hash.Keys[0], "a")
+	checkIntegerLiteral(t, hash.Values[0], 1)
+	checkIntegerLiteral(t, hash.Keys[1], 2)
+	checkStringLiteral(t, hash.Values[1], "b")
+	checkStringLiteral(t, hash.Keys[2], "c")
+	inner, ok := hash.Values[2].(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("inner is not *ast.HashLiteral. got=%T", hash.Values[2])
+	}
+	if len(inner.Keys) != 0 {
+		t.Fatalf("len(inner.Keys) is not 0. got=%d", len(inner.Keys))
+	}
+}
+
+// 中括弧の中では改行できる
+func TestMultiLineHashLiteral(t *testing.T) {
+	input := "{\n \"a\": 1,\n \"b\":\n 2\n}"
+	expr := checkCommonTestsAndParseExpression(t, input)
+	hash, ok := expr.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("hash is not *ast.HashLiteral. got=%T", expr)
+	}
+	if hash.String() != `{"a": 1, "b": 2}` {
+		t.Errorf("hash.String() is not {\"a\": 1, \"b\": 2}. got=%s", hash.String())
+	}
+}
+
+func TestHashLiteralError(t *testing.T) {
+	tests := []string {
+		`{"a"}`,
+		`{"a": 1 "b": 2}`,
+		"{",
+		"{\n",
+		"{,}",
+		"{:}",
+		`{"a": }`,
+		"x = {\n \"a\": 1\n",
+	}
+	for _, input := range tests {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("%s: parser has no errors", input)
+		}
+	}
+}
+
 // リテラルと識別子のチェック
 
 func checkIntegerLiteral(t *testing.T, exp ast.Expression, value int64) bool {
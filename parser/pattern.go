@@ -0,0 +1,256 @@
+package parser
+
+import (
+	"fmt"
+	"yokan/ast"
+	"yokan/token"
+)
+
+// match (value) {
+//   pattern => expr
+//   pattern if guard => expr
+// }
+// 腕はカンマか改行で区切る
+func (p *Parser) parseMatchExpression() ast.Expression {
+	me := &ast.MatchExpression{Token: p.curToken}
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+	me.Value = p.parseExpression()
+	if me.Value == nil || !p.expectPeek(token.RPAREN) || !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	p.nextToken()
+	for {
+		for p.curTokenIs(token.NEWLINE) || p.curTokenIs(token.COMMA) {
+			p.nextToken()
+		}
+		if p.curTokenIs(token.RBRACE) {
+			break
+		}
+		if p.curTokenIs(token.EOF) {
+			p.appendError("expected '}' at the end of match")
+			return nil
+		}
+		arm, ok := p.parseMatchArm()
+		if !ok {
+			return nil
+		}
+		me.Arms = append(me.Arms, arm)
+		p.nextToken()
+		if !p.curTokenIs(token.NEWLINE) && !p.curTokenIs(token.COMMA) && !p.curTokenIs(token.RBRACE) {
+			p.appendError(fmt.Sprintf("expected ',' or newline after match arm, got '%s' instead", p.curToken.Type))
+			return nil
+		}
+	}
+	return me
+}
+
+func (p *Parser) parseMatchArm() (ast.MatchArm, bool) {
+	arm := ast.MatchArm{Pattern: p.parsePattern()}
+	if arm.Pattern == nil {
+		return arm, false
+	}
+	// ifは組み込み関数の名前だが、パターンの後ろではガードとして読む
+	if p.peekTokenIs(token.IDENT) && p.peekToken.Literal == "if" {
+		p.nextToken()
+		p.nextToken()
+		// n if n > x => ... の x => ... を無名関数として読まないよう、ガードの終わりを覚えておく
+		saved := p.guardEnd
+		p.guardEnd = p.findArrow()
+		arm.Guard = p.parseExpression()
+		p.guardEnd = saved
+		if arm.Guard == nil {
+			return arm, false
+		}
+	}
+	if !p.expectPeek(token.ARROW) {
+		return arm, false
+	}
+	p.nextToken()
+	arm.Body = p.parseExpression()
+	return arm, arm.Body != nil
+}
+
+// カッコの外にある最初の => の位置
+func (p *Parser) findArrow() int {
+	depth := 0
+	for i := p.position; i < len(p.tokens); i++ {
+		switch p.tokens[i].Type {
+		case token.LPAREN, token.LBRACK, token.LBRACE:
+			depth += 1
+		case token.RPAREN, token.RBRACK, token.RBRACE:
+			depth -= 1
+		case token.ARROW:
+			if depth == 0 {
+				return i
+			}
+		case token.EOF:
+			return -1
+		}
+	}
+	return -1
+}
+
+// パターンを読み、同じ名前を二度束縛していないか調べる
+func (p *Parser) parsePattern() ast.Pattern {
+	pattern := p.parsePatternElement()
+	if pattern == nil {
+		return nil
+	}
+	seen := map[string]bool{ }
+	for _, name := range pattern.Names() {
+		if seen[name] {
+			p.appendError(fmt.Sprintf("%s is bound more than once in pattern %s", name, pattern.String()))
+		}
+		seen[name] = true
+	}
+	return pattern
+}
+
+func (p *Parser) parsePatternElement() ast.Pattern {
+	switch p.curToken.Type {
+	case token.INT, token.FLOAT, token.STRING, token.MINUS:
+		value := p.parsePatternLiteral()
+		if value == nil {
+			return nil
+		}
+		return &ast.LiteralPattern{Token: p.curToken, Value: value}
+	case token.IDENT:
+		switch p.curToken.Literal {
+		case "_":
+			return &ast.WildcardPattern{Token: p.curToken}
+		case "true", "false", "null":
+			return &ast.LiteralPattern{Token: p.curToken, Value: p.parseIdentifier()}
+		}
+		if isConstructorName(p.curToken.Literal) && p.peekTokenIs(token.LPAREN) {
+			return p.parseConstructorPattern()
+		}
+		return &ast.BindingPattern{Name: *p.parseIdentifier()}
+	case token.LBRACK:
+		return p.parseArrayPattern()
+	case token.LBRACE:
+		return p.parseHashPattern()
+	}
+	p.appendError(fmt.Sprintf("unexpected '%s' in pattern", p.curToken.Literal))
+	return nil
+}
+
+// 数、負の数、文字列
+func (p *Parser) parsePatternLiteral() ast.Expression {
+	switch p.curToken.Type {
+	case token.INT:
+		if lit := p.parseIntegerLiteral(); lit != nil {
+			return lit
+		}
+	case token.FLOAT:
+		if lit := p.parseFloatLiteral(); lit != nil {
+			return lit
+		}
+	case token.STRING:
+		return p.parseStringLiteral()
+	case token.MINUS:
+		if p.peekTokenIs(token.INT) || p.peekTokenIs(token.FLOAT) {
+			pe := &ast.PrefixExpression{Token: p.curToken, Operator: p.curToken.Literal}
+			p.nextToken()
+			pe.Right = p.parsePatternLiteral()
+			if pe.Right == nil {
+				return nil
+			}
+			return pe
+		}
+		p.appendError("expected number after '-' in pattern")
+	default:
+		p.appendError(fmt.Sprintf("unexpected '%s' in pattern", p.curToken.Literal))
+	}
+	return nil
+}
+
+func (p *Parser) parseArrayPattern() ast.Pattern {
+	ap := &ast.ArrayPattern{Token: p.curToken}
+	p.nextToken()
+	for !p.curTokenIs(token.RBRACK) {
+		if ap.Rest != nil {
+			p.appendError(fmt.Sprintf("...%s must be the last element of array pattern", ap.Rest.String()))
+			return nil
+		}
+		if p.curTokenIs(token.ELLIPSIS) {
+			p.nextToken()
+			if !p.curTokenIs(token.IDENT) {
+				p.appendError(fmt.Sprintf("expected name after '...' in pattern, got '%s' instead", p.curToken.Literal))
+				return nil
+			}
+			ap.Rest = p.parsePatternElement()
+		} else {
+			element := p.parsePatternElement()
+			if element == nil {
+				return nil
+			}
+			ap.Elements = append(ap.Elements, element)
+		}
+		p.nextToken()
+		if p.curTokenIs(token.COMMA) {
+			p.nextToken()
+		} else if !p.curTokenIs(token.RBRACK) {
+			p.appendError(fmt.Sprintf("expected ',' or ']' in array pattern, got '%s' instead", p.curToken.Literal))
+			return nil
+		}
+	}
+	return ap
+}
+
+func (p *Parser) parseHashPattern() ast.Pattern {
+	hp := &ast.HashPattern{Token: p.curToken}
+	p.nextToken()
+	for !p.curTokenIs(token.RBRACE) {
+		key := p.parsePatternLiteral()
+		if key == nil || !p.expectPeek(token.COLON) {
+			return nil
+		}
+		p.nextToken()
+		value := p.parsePatternElement()
+		if value == nil {
+			return nil
+		}
+		hp.Keys = append(hp.Keys, key)
+		hp.Values = append(hp.Values, value)
+		p.nextToken()
+		if p.curTokenIs(token.COMMA) {
+			p.nextToken()
+		} else if !p.curTokenIs(token.RBRACE) {
+			p.appendError(fmt.Sprintf("expected ',' or '}' in hash pattern, got '%s' instead", p.curToken.Literal))
+			return nil
+		}
+	}
+	return hp
+}
+
+// 大文字で始まりカッコの続く名前は、束縛ではなく構造体や列挙型の値として読む
+// カッコのない Empty のような名前は束縛として読み、構造体や列挙型の値を指すかどうかは評価するときに決める
+func isConstructorName(name string) bool {
+	return 'A' <= name[0] && name[0] <= 'Z'
+}
+
+// Circle(r) や Rect(w, _) や Unit()
+func (p *Parser) parseConstructorPattern() ast.Pattern {
+	cp := &ast.ConstructorPattern{Name: *p.parseIdentifier()}
+	p.nextToken()
+	p.nextToken()
+	cp.Fields = []ast.Pattern{ }
+	for !p.curTokenIs(token.RPAREN) {
+		field := p.parsePatternElement()
+		if field == nil {
+			return nil
+		}
+		cp.Fields = append(cp.Fields, field)
+		p.nextToken()
+		if p.curTokenIs(token.COMMA) {
+			p.nextToken()
+		} else if !p.curTokenIs(token.RPAREN) {
+			p.appendError(fmt.Sprintf("expected ',' or ')' in pattern %s, got '%s' instead", cp.Name.Name, p.curToken.Literal))
+			return nil
+		}
+	}
+	return cp
+}
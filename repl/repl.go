@@ -4,7 +4,9 @@ import (
 	"bufio"
 	"fmt"
 	"io"
-	
+	"strings"
+
+	"yokan/checker"
 	"yokan/lexer"
 	"yokan/parser"
 	"yokan/object"
@@ -13,17 +15,33 @@ import (
 
 const PROMPT = "> "
 
-func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
+// contractsがfalseなら、関数の requires と ensures を調べない
+func Start(in io.Reader, out io.Writer, contracts bool) {
+	// getsでも同じところから読めるよう、読み込みはひとつにまとめておく
+	reader := bufio.NewReader(in)
 	env := object.NewEnvironment()
+	env.SetInput(reader)
+	env.SetOutput(out)
+	env.SetContracts(contracts)
+	// 前の行で代入した変数の型を覚えておく
+	inferer := checker.NewInferer()
 
 	for {
-		fmt.Printf(PROMPT)
-		scanned := scanner.Scan()
-		if !scanned {
+		fmt.Fprint(out, PROMPT)
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
 			return
 		}
-		line := scanner.Text()
+		line = strings.TrimRight(line, "\r\n")
+
+		if strings.TrimSpace(line) == ":builtins" {
+			printBuildins(out, env)
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), ":type ") {
+			printType(out, inferer, strings.TrimPrefix(strings.TrimSpace(line), ":type "))
+			continue
+		}
 
 		l := lexer.New(line)
 		p := parser.New(l)
@@ -34,6 +52,11 @@ func Start(in io.Reader, out io.Writer) {
 			continue
 		}
 
+		// 推論は動的なプログラムを拒むこともあるので、型の誤りは警告にして実行は続ける
+		for _, msg := range inferer.Program(program) {
+			io.WriteString(out, "\twarning: "+msg+"\n")
+		}
+
 		evalated := evaluator.Eval(program, env)
 		if evalated != nil {
 			ret := evalated
@@ -48,4 +71,28 @@ func printParserErrors(out io.Writer, errors []string) {
 	for _, msg := range errors {
 		io.WriteString(out, "\t"+msg+"\n")
 	}
-}
\ No newline at end of file
+}
+
+// 組み込み関数の宣言と説明を一覧にする
+func printBuildins(out io.Writer, env *object.Environment) {
+	for _, sig := range object.BuildinSignatures(env) {
+		io.WriteString(out, sig.String()+"\n")
+		io.WriteString(out, "\t"+sig.Doc+"\n")
+	}
+}
+
+// :type expr で、式を評価せずに推論した型を表示する
+func printType(out io.Writer, inferer *checker.Inferer, input string) {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParserErrors(out, p.Errors())
+		return
+	}
+	t, errors := inferer.TypeOf(program)
+	if len(errors) != 0 {
+		printParserErrors(out, errors)
+		return
+	}
+	io.WriteString(out, t+"\n")
+}
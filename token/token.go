@@ -1,10 +1,25 @@
 package token
 
+import (
+	"fmt"
+)
+
 type TokenType string
 
 type Token struct {
 	Type TokenType
 	Literal string	
+	Pos Position
+}
+
+// ソースコード上の位置(どちらも1から数える)
+type Position struct {
+	Line int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
 }
 
 const (
@@ -14,14 +29,23 @@ const (
 	// 識別子 リテラル
 	IDENT  = "IDENT" // add, foobar, x, ...
 	INT    = "INT"
+	FLOAT  = "FLOAT"
 	STRING = "STRING"
 
 	// 演算子
 	ASSIGN = "="
+	ARROW  = "=>"
 	PLUS   = "+"
 	MINUS  = "-"
 	STAR   = "*"
 	SLASH  = "/"
+	PERCENT = "%"
+
+	PLUS_ASSIGN    = "+="
+	MINUS_ASSIGN   = "-="
+	STAR_ASSIGN    = "*="
+	SLASH_ASSIGN   = "/="
+	PERCENT_ASSIGN = "%="
 
 	EQ     = "=="
 	NOTEQ  = "!="
@@ -29,14 +53,41 @@ const (
 	LTEQ   = "<="
 	GT     = ">"
 	GTEQ   = ">="
+	PIPE   = "|>"
 
 	// デミリタ
 	NEWLINE = "\n"
 	COMMA   = ","
+	COLON   = ":"
+	DOT     = "."
+	ELLIPSIS = "..."
 	LPAREN  = "("
 	RPAREN  = ")"
 	LBRACE  = "{"
 	RBRACE  = "}"
 	LBRACK  = "["
 	RBRACK  = "]"
+
+	// キーワード
+	MATCH = "MATCH"
+	STRUCT = "STRUCT"
+	ENUM = "ENUM"
+	REQUIRES = "REQUIRES"
+	ENSURES = "ENSURES"
 )
+
+// true, false, nullは組み込みの変数なので、ここには入れない
+var keywords = map[string]TokenType{
+	"match": MATCH,
+	"struct": STRUCT,
+	"enum": ENUM,
+	"requires": REQUIRES,
+	"ensures": ENSURES,
+}
+
+func LookupIdent(ident string) TokenType {
+	if tok, ok := keywords[ident]; ok {
+		return tok
+	}
+	return IDENT
+}
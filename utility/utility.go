@@ -2,6 +2,8 @@ package utility
 
 import (
 	"bytes"
+	"math"
+	"strconv"
 	"strings"
 )
 
@@ -13,6 +15,25 @@ func Quote(str0 string) string {
 	return `"`+str4+`"`
 }
 
+// 読み直したときに同じ値になるように、最短の桁数で書き出す
+// 整数と区別できるよう、小数点も指数もないときは".0"を付ける
+// inf と -inf と nan は、組み込みの inf と nan を使った式として読み直せる
+func FloatString(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case math.IsNaN(f):
+		return "nan"
+	}
+	str := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(str, ".e") {
+		str += ".0"
+	}
+	return str
+}
+
 func FunctionString(args []string, body []string) string {
 	var out bytes.Buffer
 	out.WriteString("(")